@@ -0,0 +1,222 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "strconv"
+)
+
+// incidence is an edge seen from one of its nodes.
+type incidence struct {
+    to    int
+    edge  *Edge
+}
+
+// incidences returns, by index, the edges touching every node in either
+// direction. Self-loops are left out.
+func (g *Graph) incidences() [][]incidence {
+
+    idx := g.nodeIndexes()
+    adj := make([][]incidence, len(g.nodes))
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        if u == v {
+            continue
+        }
+        adj[u] = append(adj[u], incidence{v, edge})
+        adj[v] = append(adj[v], incidence{u, edge})
+    }
+
+    return adj
+}
+
+// biconnectivity holds the result of a single depth first search for
+// cut nodes, bridges and blocks.
+type biconnectivity struct {
+    cut      []bool
+    bridges  []*Edge
+    blocks   [][]*Edge
+}
+
+func (g *Graph) biconnectivity() biconnectivity {
+
+    adj := g.incidences()
+    n := len(g.nodes)
+
+    b := biconnectivity{cut: make([]bool, n)}
+    order := make([]int, n)
+    low := make([]int, n)
+    for i := range order {
+        order[i] = -1
+    }
+
+    counter := 0
+    stack := make([]*Edge, 0)
+
+    var visit func(u int, via *Edge)
+    visit = func(u int, via *Edge) {
+        order[u] = counter
+        low[u] = counter
+        counter++
+        children := 0
+
+        for _, in := range adj[u] {
+            if in.edge == via {
+                continue
+            }
+
+            v := in.to
+            if order[v] < 0 {
+                children++
+                stack = append(stack, in.edge)
+                visit(v, in.edge)
+
+                if low[v] < low[u] {
+                    low[u] = low[v]
+                }
+                if low[v] > order[u] {
+                    b.bridges = append(b.bridges, in.edge)
+                }
+                if low[v] >= order[u] {
+                    if via != nil {
+                        b.cut[u] = true
+                    }
+
+                    // pop the block rooted at this edge
+                    block := make([]*Edge, 0)
+                    for {
+                        top := stack[len(stack)-1]
+                        stack = stack[:len(stack)-1]
+                        block = append(block, top)
+                        if top == in.edge {
+                            break
+                        }
+                    }
+                    b.blocks = append(b.blocks, block)
+                }
+            } else if order[v] < order[u] {
+                stack = append(stack, in.edge)
+                if order[v] < low[u] {
+                    low[u] = order[v]
+                }
+            }
+        }
+
+        if via == nil && children > 1 {
+            b.cut[u] = true
+        }
+    }
+
+    for u := range g.nodes {
+        if order[u] < 0 {
+            visit(u, nil)
+        }
+    }
+
+    return b
+}
+
+// ArticulationPoints returns the nodes whose removal would disconnect
+// the part of the graph they belong to. Edges are treated as undirected.
+func (g *Graph) ArticulationPoints() []*Node {
+
+    out := make([]*Node, 0)
+
+    if g == nil {
+        return out
+    }
+
+    for i, cut := range g.biconnectivity().cut {
+        if cut {
+            out = append(out, g.nodes[i])
+        }
+    }
+
+    return out
+}
+
+// Bridges returns the edges whose removal would disconnect the part of
+// the graph they belong to. Edges are treated as undirected.
+func (g *Graph) Bridges() []*Edge {
+
+    if g == nil {
+        return make([]*Edge, 0)
+    }
+
+    return g.biconnectivity().bridges
+}
+
+// BiconnectedComponents returns the edges of every biconnected
+// component, the maximal groups of edges that stay connected when any
+// single node is removed. Edges are treated as undirected and
+// self-loops belong to no component.
+func (g *Graph) BiconnectedComponents() [][]*Edge {
+
+    if g == nil {
+        return make([][]*Edge, 0)
+    }
+
+    return g.biconnectivity().blocks
+}
+
+// BlockCutTree returns a new graph with a node for every biconnected
+// component and every articulation point, linking each component to the
+// articulation points it contains. Component nodes are given the id
+// "block:<n>" and articulation points keep the id and name of the
+// original node. Every node carries a "type" property of either "block"
+// or "cut".
+func (g *Graph) BlockCutTree() (*Graph, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    b := g.biconnectivity()
+    tree := NewGraph(g.id)
+    cuts := make(map[*Node]*Node)
+
+    for i, cut := range b.cut {
+        if !cut {
+            continue
+        }
+        node := g.nodes[i]
+        n, err := tree.AddNode(node.GetProperty("id"), node.GetProperty("name"))
+        if err != nil {
+            return nil, err
+        }
+        n.AddProperty("type", "cut")
+        cuts[node] = n
+    }
+
+    for i, block := range b.blocks {
+        id := "block:" + strconv.Itoa(i)
+        n, err := tree.AddNode(id, "block")
+        if err != nil {
+            return nil, err
+        }
+        n.AddProperty("type", "block")
+
+        linked := make(map[*Node]bool)
+        for _, edge := range block {
+            for _, end := range []*Node{edge.ParentNode, edge.ChildNode} {
+                cut, ok := cuts[end]
+                if !ok || linked[end] {
+                    continue
+                }
+                linked[end] = true
+                err = tree.AddEdge(id+"-"+cut.GetProperty("id"), "contains", 1, n, cut)
+                if err != nil {
+                    return nil, err
+                }
+            }
+        }
+    }
+
+    return tree, nil
+}
@@ -0,0 +1,65 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "sort"
+    "strings"
+    "testing"
+)
+
+// edgeIDs lists the sorted ids of edges.
+func edgeIDs(edges []*Edge) string {
+    ids := make([]string, len(edges))
+    for i, e := range edges {
+        ids[i] = e.GetProperty("id")
+    }
+    sort.Strings(ids)
+    return strings.Join(ids, " ")
+}
+
+func TestBiconnectivity(t *testing.T) {
+
+    // two triangles joined by the bridge e6, with a pendant g on f and
+    // a pair of parallel edges between g and h, which are no bridge
+    g := edgeGraph(t, "a b", "b c", "c a", "d e", "e f", "f d", "c d", "f g", "g h", "h g")
+
+    points := make([]string, 0)
+    for _, n := range g.ArticulationPoints() {
+        points = append(points, n.GetProperty("id"))
+    }
+    sort.Strings(points)
+    if got := strings.Join(points, " "); got != "c d f g" {
+        t.Errorf("articulation points %s, want c d f g", got)
+    }
+    if got := edgeIDs(g.Bridges()); got != "e6 e7" {
+        t.Errorf("bridges %s, want e6 e7", got)
+    }
+
+    blocks := make([]string, 0)
+    for _, block := range g.BiconnectedComponents() {
+        blocks = append(blocks, edgeIDs(block))
+    }
+    sort.Strings(blocks)
+    if got := strings.Join(blocks, ", "); got != "e0 e1 e2, e3 e4 e5, e6, e7, e8 e9" {
+        t.Errorf("components %s, want e0 e1 e2, e3 e4 e5, e6, e7, e8 e9", got)
+    }
+
+    tree, err := g.BlockCutTree()
+    if err != nil {
+        t.Fatal(err)
+    }
+    kinds := make(map[string]int)
+    for _, n := range tree.nodes {
+        kinds[n.GetProperty("type")]++
+    }
+    if kinds["block"] != 5 || kinds["cut"] != 4 || len(tree.edges()) != 8 {
+        t.Errorf("block-cut tree has %v nodes and %d edges, want 5 blocks, 4 cuts and 8 edges", kinds, len(tree.edges()))
+    }
+    if _, ok := tree.GetNodeById("c"); !ok {
+        t.Errorf("cut node c missing from the block-cut tree")
+    }
+}
@@ -0,0 +1,385 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+)
+
+// Bipartition holds the two sides of a bipartite graph. When the graph
+// is not bipartite OddCycle lists the nodes of an odd length cycle in
+// order as proof, and Left and Right are empty.
+type Bipartition struct {
+    Left      []*Node
+    Right     []*Node
+    OddCycle  []*Node
+}
+
+// neighbours returns the nodes linked to every node in either
+// direction, by index, ignoring edges to nodes outside the graph.
+func (g *Graph) neighbours() [][]int {
+
+    idx := g.nodeIndexes()
+    adj := make([][]int, len(g.nodes))
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        adj[u] = append(adj[u], v)
+        if u != v {
+            adj[v] = append(adj[v], u)
+        }
+    }
+
+    return adj
+}
+
+// IsBipartite returns true if the nodes can be split in two sides with
+// every edge linking one side to the other. Edges are treated as
+// undirected.
+func (g *Graph) IsBipartite() (Bipartition, bool) {
+
+    var b Bipartition
+
+    if g == nil {
+        return b, false
+    }
+
+    adj := g.neighbours()
+    color := make([]int, len(g.nodes))
+    depth := make([]int, len(g.nodes))
+    parent := make([]int, len(g.nodes))
+    for i := range color {
+        color[i] = -1
+    }
+
+    for root := range g.nodes {
+        if color[root] >= 0 {
+            continue
+        }
+
+        color[root] = 0
+        parent[root] = -1
+        queue := []int{root}
+
+        for len(queue) > 0 {
+            u := queue[0]
+            queue = queue[1:]
+
+            for _, v := range adj[u] {
+                if color[v] < 0 {
+                    color[v] = 1 - color[u]
+                    depth[v] = depth[u] + 1
+                    parent[v] = u
+                    queue = append(queue, v)
+                } else if color[v] == color[u] {
+                    b.OddCycle = g.oddCycle(u, v, parent, depth)
+                    return b, false
+                }
+            }
+        }
+    }
+
+    for i, node := range g.nodes {
+        if color[i] == 0 {
+            b.Left = append(b.Left, node)
+        } else {
+            b.Right = append(b.Right, node)
+        }
+    }
+
+    return b, true
+}
+
+// oddCycle joins the breadth first tree paths of u and v, which share
+// a color and are linked by an edge, into a cycle.
+func (g *Graph) oddCycle(u, v int, parent, depth []int) []*Node {
+
+    head := make([]*Node, 0)
+    tail := make([]*Node, 0)
+
+    for u != v {
+        if depth[u] >= depth[v] {
+            head = append(head, g.nodes[u])
+            u = parent[u]
+        } else {
+            tail = append(tail, g.nodes[v])
+            v = parent[v]
+        }
+    }
+    head = append(head, g.nodes[u])
+
+    for i := len(tail) - 1; i >= 0; i-- {
+        head = append(head, tail[i])
+    }
+
+    return head
+}
+
+// sides validates the left and right node sets of a bipartite problem
+// and returns their position within each set.
+func (g *Graph) sides(left []*Node, right []*Node) (map[*Node]int, map[*Node]int, error) {
+
+    idx := g.nodeIndexes()
+    l := make(map[*Node]int, len(left))
+    r := make(map[*Node]int, len(right))
+
+    for i, node := range left {
+        if _, ok := idx[node]; !ok {
+            return nil, nil, errors.New("Node is not part of the graph")
+        }
+        l[node] = i
+    }
+    for i, node := range right {
+        if _, ok := idx[node]; !ok {
+            return nil, nil, errors.New("Node is not part of the graph")
+        }
+        if _, ok := l[node]; ok {
+            return nil, nil, errors.New("Node is on both sides")
+        }
+        r[node] = i
+    }
+
+    return l, r, nil
+}
+
+// crossEdges returns the edges between every left and right node by
+// position, in either direction.
+func (g *Graph) crossEdges(l map[*Node]int, r map[*Node]int) [][]*Edge {
+
+    out := make([][]*Edge, len(l))
+
+    for _, edge := range g.edges() {
+        if i, ok := l[edge.ParentNode]; ok {
+            if _, ok := r[edge.ChildNode]; ok {
+                out[i] = append(out[i], edge)
+            }
+        } else if i, ok := l[edge.ChildNode]; ok {
+            if _, ok := r[edge.ParentNode]; ok {
+                out[i] = append(out[i], edge)
+            }
+        }
+    }
+
+    return out
+}
+
+// otherEnd returns the node on the far side of an edge from n.
+func otherEnd(e *Edge, n *Node) *Node {
+    if e.ParentNode == n {
+        return e.ChildNode
+    }
+    return e.ParentNode
+}
+
+// MaximumMatching returns a largest set of edges between the left and
+// right nodes that share no node, using the Hopcroft-Karp algorithm.
+// Edge direction is ignored.
+func (g *Graph) MaximumMatching(left []*Node, right []*Node) ([]*Edge, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    l, r, err := g.sides(left, right)
+    if err != nil {
+        return nil, err
+    }
+    cross := g.crossEdges(l, r)
+
+    const free = -1
+    matchL := make([]*Edge, len(left))
+    matchR := make([]int, len(right))
+    for i := range matchR {
+        matchR[i] = free
+    }
+
+    dist := make([]int, len(left))
+
+    // bfs layers the free left nodes and reports whether an augmenting
+    // path exists
+    bfs := func() bool {
+        queue := make([]int, 0)
+        for u := range left {
+            if matchL[u] == nil {
+                dist[u] = 0
+                queue = append(queue, u)
+            } else {
+                dist[u] = math.MaxInt32
+            }
+        }
+
+        found := false
+        for len(queue) > 0 {
+            u := queue[0]
+            queue = queue[1:]
+            for _, edge := range cross[u] {
+                w := matchR[r[otherEnd(edge, left[u])]]
+                if w == free {
+                    found = true
+                } else if dist[w] == math.MaxInt32 {
+                    dist[w] = dist[u] + 1
+                    queue = append(queue, w)
+                }
+            }
+        }
+        return found
+    }
+
+    var dfs func(u int) bool
+    dfs = func(u int) bool {
+        for _, edge := range cross[u] {
+            v := r[otherEnd(edge, left[u])]
+            w := matchR[v]
+            if w == free || (dist[w] == dist[u]+1 && dfs(w)) {
+                matchL[u] = edge
+                matchR[v] = u
+                return true
+            }
+        }
+        dist[u] = math.MaxInt32
+        return false
+    }
+
+    for bfs() {
+        for u := range left {
+            if matchL[u] == nil {
+                dfs(u)
+            }
+        }
+    }
+
+    out := make([]*Edge, 0)
+    for _, edge := range matchL {
+        if edge != nil {
+            out = append(out, edge)
+        }
+    }
+
+    return out, nil
+}
+
+// Assignment returns a matching that pairs every node of the smaller
+// side with a node of the other side at the lowest total distance,
+// using the Hungarian algorithm. Edge direction is ignored and the
+// shortest edge is used when nodes are linked more than once. An error
+// is returned if no such complete matching exists.
+func (g *Graph) Assignment(left []*Node, right []*Node) ([]*Edge, float64, error) {
+
+    if g == nil {
+        return nil, 0, errors.New("Graph is empty or nil")
+    }
+
+    if len(left) > len(right) {
+        left, right = right, left
+    }
+
+    l, r, err := g.sides(left, right)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    n := len(left)
+    m := len(right)
+    if n == 0 {
+        return []*Edge{}, 0, nil
+    }
+
+    // cheapest edge for every pair, missing pairs cost more than any
+    // complete assignment made of real edges
+    best := make([][]*Edge, n)
+    for i := range best {
+        best[i] = make([]*Edge, m)
+    }
+    missing := 1.0
+    for i, edges := range g.crossEdges(l, r) {
+        for _, edge := range edges {
+            j := r[otherEnd(edge, left[i])]
+            if best[i][j] == nil || edge.Distance < best[i][j].Distance {
+                best[i][j] = edge
+            }
+            missing += edge.Distance
+        }
+    }
+
+    cost := func(i, j int) float64 {
+        if best[i][j] == nil {
+            return missing
+        }
+        return best[i][j].Distance
+    }
+
+    // potentials and matching use 1-based indexes, 0 is a sentinel column
+    u := make([]float64, n+1)
+    v := make([]float64, m+1)
+    p := make([]int, m+1)
+    way := make([]int, m+1)
+
+    for i := 1; i <= n; i++ {
+        p[0] = i
+        j0 := 0
+        minv := make([]float64, m+1)
+        used := make([]bool, m+1)
+        for j := range minv {
+            minv[j] = math.Inf(1)
+        }
+
+        for p[j0] != 0 {
+            used[j0] = true
+            i0 := p[j0]
+            delta := math.Inf(1)
+            j1 := 0
+
+            for j := 1; j <= m; j++ {
+                if used[j] {
+                    continue
+                }
+                cur := cost(i0-1, j-1) - u[i0] - v[j]
+                if cur < minv[j] {
+                    minv[j] = cur
+                    way[j] = j0
+                }
+                if minv[j] < delta {
+                    delta = minv[j]
+                    j1 = j
+                }
+            }
+
+            for j := 0; j <= m; j++ {
+                if used[j] {
+                    u[p[j]] += delta
+                    v[j] -= delta
+                } else {
+                    minv[j] -= delta
+                }
+            }
+            j0 = j1
+        }
+
+        for j0 != 0 {
+            j1 := way[j0]
+            p[j0] = p[j1]
+            j0 = j1
+        }
+    }
+
+    out := make([]*Edge, 0, n)
+    total := 0.0
+    for j := 1; j <= m; j++ {
+        if p[j] == 0 {
+            continue
+        }
+        edge := best[p[j]-1][j-1]
+        if edge == nil {
+            return nil, 0, errors.New("No complete assignment exists")
+        }
+        out = append(out, edge)
+        total += edge.Distance
+    }
+
+    return out, total, nil
+}
@@ -0,0 +1,92 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "testing"
+)
+
+func TestIsBipartite(t *testing.T) {
+
+    square := edgeGraph(t, "a b", "b c", "c d", "d a")
+    sides, ok := square.IsBipartite()
+    if !ok || nodeNames(sides.Left) != "a c" || nodeNames(sides.Right) != "b d" {
+        t.Errorf("square: %v sides %s | %s, want a c | b d", ok, nodeNames(sides.Left), nodeNames(sides.Right))
+    }
+
+    triangle := edgeGraph(t, "a b", "b c", "c a", "c d")
+    sides, ok = triangle.IsBipartite()
+    if ok || len(sides.OddCycle) != 3 || len(sides.Left) != 0 {
+        t.Errorf("triangle: %v odd cycle %s", ok, nodeNames(sides.OddCycle))
+    }
+}
+
+// pick returns the named nodes of g.
+func pick(g *Graph, ids ...string) []*Node {
+    out := make([]*Node, len(ids))
+    for i, id := range ids {
+        out[i], _ = g.GetNodeById(id)
+    }
+    return out
+}
+
+func TestMaximumMatching(t *testing.T) {
+
+    // a greedy matching taking a x first ends with two edges
+    g := edgeGraph(t, "a x", "a y", "b x", "c y", "c z")
+    edges, err := g.MaximumMatching(pick(g, "a", "b", "c"), pick(g, "x", "y", "z"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    pairs := make(map[string]string)
+    for _, e := range edges {
+        pairs[e.ParentNode.GetProperty("id")] = e.ChildNode.GetProperty("id")
+    }
+    if len(edges) != 3 || pairs["a"] != "y" || pairs["b"] != "x" || pairs["c"] != "z" {
+        t.Errorf("matching %v, want a y, b x, c z", pairs)
+    }
+
+    if _, err := g.MaximumMatching(pick(g, "a", "x"), pick(g, "x")); err == nil {
+        t.Errorf("a matching with a node on both sides did not fail")
+    }
+}
+
+func TestAssignment(t *testing.T) {
+
+    // the costs
+    //
+    //      x  y  z
+    //  a   4  1  3
+    //  b   2  6  5
+    //  c   3  2  2
+    //
+    // are lowest with a y, b x and c z at 1 + 2 + 2
+    g := edgeGraph(t,
+        "a x 4", "a y 1", "a z 3",
+        "b x 2", "b y 6", "b z 5",
+        "c x 3", "c y 2", "c z 2")
+    edges, cost, err := g.Assignment(pick(g, "a", "b", "c"), pick(g, "x", "y", "z"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    pairs := make(map[string]string)
+    for _, e := range edges {
+        pairs[e.ParentNode.GetProperty("id")] = e.ChildNode.GetProperty("id")
+    }
+    if cost != 5 || pairs["a"] != "y" || pairs["b"] != "x" || pairs["c"] != "z" {
+        t.Errorf("assignment %v at %v, want a y, b x, c z at 5", pairs, cost)
+    }
+
+    // the smaller side is assigned, but x is the only choice of a and b
+    g = edgeGraph(t, "a x 2", "b x 1")
+    if edges, cost, err := g.Assignment(pick(g, "a", "b"), pick(g, "x")); err != nil || len(edges) != 1 || cost != 1 {
+        t.Errorf("assigning the smaller side gave %d edges at %v, %v, want b x at 1", len(edges), cost, err)
+    }
+    g = edgeGraph(t, "a x", "b x", "c y")
+    if _, _, err := g.Assignment(pick(g, "a", "b"), pick(g, "x", "y")); err == nil {
+        t.Errorf("an assignment without a complete matching did not fail")
+    }
+}
@@ -0,0 +1,238 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "sort"
+)
+
+// neighbourSets returns, by index, the sorted distinct neighbours of
+// every node, ignoring edge direction and self-loops.
+func (g *Graph) neighbourSets() [][]int {
+
+    adj := g.neighbours()
+    out := make([][]int, len(adj))
+
+    for u := range adj {
+        sort.Ints(adj[u])
+        set := make([]int, 0, len(adj[u]))
+        for i, v := range adj[u] {
+            if v != u && (i == 0 || v != adj[u][i-1]) {
+                set = append(set, v)
+            }
+        }
+        out[u] = set
+    }
+
+    return out
+}
+
+// intersect returns the values found in both sorted slices.
+func intersect(a []int, b []int) []int {
+
+    out := make([]int, 0)
+    for i, j := 0, 0; i < len(a) && j < len(b); {
+        switch {
+        case a[i] < b[j]:
+            i++
+        case a[i] > b[j]:
+            j++
+        default:
+            out = append(out, a[i])
+            i++
+            j++
+        }
+    }
+
+    return out
+}
+
+// degeneracyOrder returns the node indexes in the order they are
+// removed when always removing a node of smallest remaining degree.
+func degeneracyOrder(adj [][]int) []int {
+
+    n := len(adj)
+    degree := make([]int, n)
+    maxDegree := 0
+    for u := range adj {
+        degree[u] = len(adj[u])
+        if degree[u] > maxDegree {
+            maxDegree = degree[u]
+        }
+    }
+
+    buckets := make([][]int, maxDegree+1)
+    for u := range adj {
+        buckets[degree[u]] = append(buckets[degree[u]], u)
+    }
+
+    removed := make([]bool, n)
+    order := make([]int, 0, n)
+    for d := 0; len(order) < n; {
+        if len(buckets[d]) == 0 {
+            d++
+            continue
+        }
+
+        u := buckets[d][len(buckets[d])-1]
+        buckets[d] = buckets[d][:len(buckets[d])-1]
+        if removed[u] || degree[u] != d {
+            continue
+        }
+
+        removed[u] = true
+        order = append(order, u)
+        for _, v := range adj[u] {
+            if !removed[v] {
+                degree[v]--
+                buckets[degree[v]] = append(buckets[degree[v]], v)
+                if degree[v] < d {
+                    d = degree[v]
+                }
+            }
+        }
+    }
+
+    return order
+}
+
+// cliqueSearch holds the state of a Bron-Kerbosch run.
+type cliqueSearch struct {
+    adj      [][]int
+    minSize  int
+    found    func([]int)
+}
+
+// expand reports every maximal clique containing r, extended from p and
+// excluding x, using the candidate with the most neighbours in p as
+// pivot.
+func (c *cliqueSearch) expand(r []int, p []int, x []int) {
+
+    if len(r)+len(p) < c.minSize {
+        return
+    }
+
+    if len(p) == 0 {
+        if len(x) == 0 {
+            c.found(r)
+        }
+        return
+    }
+
+    pivot, most := -1, -1
+    for _, list := range [][]int{p, x} {
+        for _, u := range list {
+            if n := len(intersect(p, c.adj[u])); n > most {
+                pivot, most = u, n
+            }
+        }
+    }
+
+    skip := make(map[int]bool)
+    for _, v := range c.adj[pivot] {
+        skip[v] = true
+    }
+
+    for _, v := range append([]int{}, p...) {
+        if skip[v] {
+            continue
+        }
+
+        next := make([]int, len(r)+1)
+        copy(next, r)
+        next[len(r)] = v
+        c.expand(next, intersect(p, c.adj[v]), intersect(x, c.adj[v]))
+
+        // move v from p to x, keeping both sorted
+        for i, w := range p {
+            if w == v {
+                p = append(p[:i:i], p[i+1:]...)
+                break
+            }
+        }
+        i := sort.SearchInts(x, v)
+        x = append(x[:i:i], append([]int{v}, x[i:]...)...)
+
+        if len(r)+len(p) < c.minSize {
+            return
+        }
+    }
+}
+
+// run starts the search from every node in degeneracy order.
+func (c *cliqueSearch) run() {
+
+    order := degeneracyOrder(c.adj)
+    position := make([]int, len(order))
+    for i, u := range order {
+        position[u] = i
+    }
+
+    for i, u := range order {
+        p := make([]int, 0)
+        x := make([]int, 0)
+        for _, v := range c.adj[u] {
+            if position[v] > i {
+                p = append(p, v)
+            } else {
+                x = append(x, v)
+            }
+        }
+        c.expand([]int{u}, p, x)
+    }
+}
+
+// MaximalCliques returns every clique, a set of nodes all linked to each
+// other, that can not be extended by another node and holds at least
+// minSize nodes. It uses the Bron-Kerbosch algorithm with pivoting over
+// a degeneracy ordering. Edges are treated as undirected.
+func (g *Graph) MaximalCliques(minSize int) [][]*Node {
+
+    out := make([][]*Node, 0)
+
+    if g == nil {
+        return out
+    }
+
+    c := &cliqueSearch{adj: g.neighbourSets(), minSize: minSize}
+    c.found = func(r []int) {
+        clique := make([]*Node, len(r))
+        for i, u := range r {
+            clique[i] = g.nodes[u]
+        }
+        out = append(out, clique)
+    }
+    c.run()
+
+    return out
+}
+
+// MaximumClique returns a largest clique of the graph. Edges are treated
+// as undirected.
+func (g *Graph) MaximumClique() []*Node {
+
+    out := make([]*Node, 0)
+
+    if g == nil {
+        return out
+    }
+
+    // only look for cliques larger than the best one found so far
+    c := &cliqueSearch{adj: g.neighbourSets(), minSize: 1}
+    c.found = func(r []int) {
+        if len(r) <= len(out) {
+            return
+        }
+        out = make([]*Node, len(r))
+        for i, u := range r {
+            out[i] = g.nodes[u]
+        }
+        c.minSize = len(r) + 1
+    }
+    c.run()
+
+    return out
+}
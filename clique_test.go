@@ -0,0 +1,56 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "sort"
+    "strings"
+    "testing"
+)
+
+// cliqueNames lists cliques as sorted node ids, in sorted order.
+func cliqueNames(cliques [][]*Node) string {
+    out := make([]string, len(cliques))
+    for i, c := range cliques {
+        ids := strings.Fields(nodeNames(c))
+        sort.Strings(ids)
+        out[i] = strings.Join(ids, "")
+    }
+    sort.Strings(out)
+    return strings.Join(out, " ")
+}
+
+func TestCliques(t *testing.T) {
+
+    // K4 a b c d, the triangle d e f, the edge f g and h on its own
+    g := edgeGraph(t, "a b", "a c", "a d", "b c", "b d", "c d", "d e", "e f", "f d", "g f")
+    g.AddNode("h", "h")
+
+    if got := cliqueNames(g.MaximalCliques(1)); got != "abcd def fg h" {
+        t.Errorf("maximal cliques %s, want abcd def fg h", got)
+    }
+    if got := cliqueNames(g.MaximalCliques(3)); got != "abcd def" {
+        t.Errorf("maximal cliques of 3 or more %s, want abcd def", got)
+    }
+    if got := cliqueNames([][]*Node{g.MaximumClique()}); got != "abcd" {
+        t.Errorf("maximum clique %s, want abcd", got)
+    }
+
+    // the octahedron links every pair of its six corners but the three
+    // opposite ones, giving eight triangular faces
+    octahedron := edgeGraph(t,
+        "a c", "a d", "a e", "a f", "b c", "b d", "b e", "b f",
+        "c e", "c f", "d e", "d f")
+    if got := g.MaximalCliques(4); len(got) != 1 {
+        t.Errorf("%d cliques of 4 or more, want 1", len(got))
+    }
+    if got := octahedron.MaximalCliques(1); len(got) != 8 {
+        t.Errorf("octahedron has %d maximal cliques, want 8: %s", len(got), cliqueNames(got))
+    }
+    if got := octahedron.MaximumClique(); len(got) != 3 {
+        t.Errorf("octahedron maximum clique has %d nodes, want 3", len(got))
+    }
+}
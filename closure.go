@@ -0,0 +1,210 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+)
+
+// successors returns, by index, the child of every edge leaving each
+// node of the graph.
+func (g *Graph) successors() [][]int {
+
+    idx := g.nodeIndexes()
+    adj := make([][]int, len(g.nodes))
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        adj[u] = append(adj[u], idx[edge.ChildNode])
+    }
+
+    return adj
+}
+
+// topologicalOrder returns the node indexes ordered so that every edge
+// points forward, or false if the graph has a cycle.
+func topologicalOrder(adj [][]int) ([]int, bool) {
+
+    in := make([]int, len(adj))
+    for u := range adj {
+        for _, v := range adj[u] {
+            in[v]++
+        }
+    }
+
+    order := make([]int, 0, len(adj))
+    for u := range adj {
+        if in[u] == 0 {
+            order = append(order, u)
+        }
+    }
+
+    for i := 0; i < len(order); i++ {
+        for _, v := range adj[order[i]] {
+            in[v]--
+            if in[v] == 0 {
+                order = append(order, v)
+            }
+        }
+    }
+
+    return order, len(order) == len(adj)
+}
+
+// copyNode adds a copy of node, with all its properties, to the graph out.
+func copyNode(out *Graph, node *Node) (*Node, error) {
+
+    props := node.copyProperties()
+
+    n, err := out.AddNode(props["id"], props["name"])
+    if err != nil {
+        return nil, err
+    }
+    for key, value := range props {
+        if err := n.AddProperty(key, value); err != nil {
+            return nil, err
+        }
+    }
+    for _, label := range node.Labels() {
+        if err := n.AddLabel(label); err != nil {
+            return nil, err
+        }
+    }
+
+    return n, nil
+}
+
+// copyNodes adds a copy of every node of g to the graph out and returns
+// the copies by index.
+func (g *Graph) copyNodes(out *Graph) ([]*Node, error) {
+
+    nodes := make([]*Node, len(g.nodes))
+
+    for i, node := range g.nodes {
+        n, err := copyNode(out, node)
+        if err != nil {
+            return nil, err
+        }
+        nodes[i] = n
+    }
+
+    return nodes, nil
+}
+
+// Reachability returns a matrix, indexed in the order nodes were added,
+// holding true at [i][j] when a path leads from node i to node j. A node
+// only reaches itself if it lies on a cycle.
+func (g *Graph) Reachability() [][]bool {
+
+    if g == nil {
+        return make([][]bool, 0)
+    }
+
+    adj := g.successors()
+    reach := make([][]bool, len(adj))
+
+    for s := range adj {
+        reach[s] = make([]bool, len(adj))
+        queue := []int{s}
+        for len(queue) > 0 {
+            u := queue[0]
+            queue = queue[1:]
+            for _, v := range adj[u] {
+                if !reach[s][v] {
+                    reach[s][v] = true
+                    queue = append(queue, v)
+                }
+            }
+        }
+    }
+
+    return reach
+}
+
+// TransitiveClosure returns a new graph holding a copy of every node and
+// an edge from each node to every node it can reach. Closure edges are
+// named "reaches", have a weight of 1 and the id "<parent id>-><child id>".
+func (g *Graph) TransitiveClosure() (*Graph, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    closure := NewGraph(g.id)
+    nodes, err := g.copyNodes(closure)
+    if err != nil {
+        return nil, err
+    }
+
+    for i, row := range g.Reachability() {
+        for j, ok := range row {
+            if !ok {
+                continue
+            }
+            id := nodes[i].GetProperty("id") + "->" + nodes[j].GetProperty("id")
+            if err := closure.AddEdge(id, "reaches", 1, nodes[i], nodes[j]); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    return closure, nil
+}
+
+// TransitiveReduction splits the edges of a directed acyclic graph into
+// the smallest set that keeps every node reaching the same nodes, and
+// the edges that can be removed without changing reachability. Parallel
+// edges are reduced to the first one found. An error is returned if the
+// graph has a cycle.
+func (g *Graph) TransitiveReduction() (keep []*Edge, remove []*Edge, err error) {
+
+    if g == nil {
+        return nil, nil, errors.New("Graph is empty or nil")
+    }
+
+    adj := g.successors()
+    if _, ok := topologicalOrder(adj); !ok {
+        return nil, nil, errors.New("Graph has a cycle")
+    }
+
+    reach := g.Reachability()
+    idx := g.nodeIndexes()
+    keep = make([]*Edge, 0)
+    remove = make([]*Edge, 0)
+
+    for u, node := range g.nodes {
+        children := make(map[int]bool)
+        for _, v := range adj[u] {
+            children[v] = true
+        }
+
+        seen := make(map[int]bool)
+        for _, edge := range node.ChildEdges() {
+            v, ok := idx[edge.ChildNode]
+            if !ok {
+                continue
+            }
+
+            // v is redundant when another child already reaches it
+            redundant := seen[v]
+            for w := range children {
+                if w != v && reach[w][v] {
+                    redundant = true
+                    break
+                }
+            }
+            seen[v] = true
+
+            if redundant {
+                remove = append(remove, edge)
+            } else {
+                keep = append(keep, edge)
+            }
+        }
+    }
+
+    return keep, remove, nil
+}
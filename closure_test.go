@@ -0,0 +1,63 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "fmt"
+    "testing"
+)
+
+func TestTransitiveClosure(t *testing.T) {
+
+    // a chain a b c with d reaching it and a cycle between e and f
+    g := edgeGraph(t, "a b", "b c", "d b", "e f", "f e")
+    g.nodes[0].AddLabel("Start")
+
+    reach := make([]string, 0)
+    for i, row := range g.Reachability() {
+        for j, ok := range row {
+            if ok {
+                reach = append(reach, g.nodes[i].GetProperty("id")+g.nodes[j].GetProperty("id"))
+            }
+        }
+    }
+    if got := fmt.Sprint(reach); got != "[ab ac bc db dc ee ef fe ff]" {
+        t.Errorf("reachability %s, want [ab ac bc db dc ee ef fe ff]", got)
+    }
+
+    closure, err := g.TransitiveClosure()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(closure.nodes) != len(g.nodes) || len(closure.edges()) != len(reach) {
+        t.Errorf("closure has %d nodes and %d edges, want %d and %d", len(closure.nodes), len(closure.edges()), len(g.nodes), len(reach))
+    }
+    a, _ := closure.GetNodeById("a")
+    if len(a.EdgesOfType("reaches")) != 2 || !a.HasLabel("Start") {
+        t.Errorf("closure node a has edges %d and labels %v", len(a.Edges), a.Labels())
+    }
+    if a == g.nodes[0] {
+        t.Errorf("closure shares nodes with the graph")
+    }
+}
+
+func TestTransitiveReduction(t *testing.T) {
+
+    // a b c d is a chain with the shortcuts e3, e4 and e5 and the
+    // parallel edge e6
+    g := edgeGraph(t, "a b", "b c", "c d", "a c", "a d", "b d", "c d")
+    keep, remove, err := g.TransitiveReduction()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if edgeIDs(keep) != "e0 e1 e2" || edgeIDs(remove) != "e3 e4 e5 e6" {
+        t.Errorf("keep %s and remove %s, want e0 e1 e2 and e3 e4 e5 e6", edgeIDs(keep), edgeIDs(remove))
+    }
+
+    if _, _, err := edgeGraph(t, "a b", "b a").TransitiveReduction(); err == nil {
+        t.Errorf("reducing a cycle did not fail")
+    }
+}
@@ -0,0 +1,133 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+)
+
+// ColoringStrategy selects the order in which nodes are colored.
+type ColoringStrategy int
+
+const (
+    // ColorInOrder colors nodes in the order they were added.
+    ColorInOrder ColoringStrategy = iota
+    // ColorWelshPowell colors nodes by decreasing degree.
+    ColorWelshPowell
+    // ColorDSatur colors next the node with the most distinctly
+    // colored neighbours, breaking ties by degree.
+    ColorDSatur
+)
+
+// Coloring holds the color of every node, colors are numbered from 0.
+type Coloring struct {
+    Color  map[*Node]int
+    Count  int
+}
+
+// Color assigns every node a color so that no two linked nodes share
+// one, using a greedy algorithm with the given strategy. Edges are
+// treated as undirected and self-loops are ignored. If property is not
+// empty the color of each node is written back to the node under that
+// property key, and the graph schema rejecting one fails the coloring.
+func (g *Graph) Color(strategy ColoringStrategy, property string) (Coloring, error) {
+
+    if g == nil {
+        return Coloring{}, errors.New("Graph is empty or nil")
+    }
+
+    adj := g.neighbours()
+    n := len(g.nodes)
+
+    color := make([]int, n)
+    for i := range color {
+        color[i] = -1
+    }
+
+    degree := make([]int, n)
+    for u := range adj {
+        for _, v := range adj[u] {
+            if v != u {
+                degree[u]++
+            }
+        }
+    }
+
+    // smallest color not used by a neighbour of u
+    pick := func(u int) int {
+        used := make(map[int]bool)
+        for _, v := range adj[u] {
+            if v != u && color[v] >= 0 {
+                used[color[v]] = true
+            }
+        }
+        c := 0
+        for used[c] {
+            c++
+        }
+        return c
+    }
+
+    switch strategy {
+    case ColorInOrder, ColorWelshPowell:
+        order := make([]int, n)
+        for i := range order {
+            order[i] = i
+        }
+        if strategy == ColorWelshPowell {
+            sort.SliceStable(order, func(a, b int) bool {
+                return degree[order[a]] > degree[order[b]]
+            })
+        }
+        for _, u := range order {
+            color[u] = pick(u)
+        }
+
+    case ColorDSatur:
+        saturation := make([]map[int]bool, n)
+        for i := range saturation {
+            saturation[i] = make(map[int]bool)
+        }
+
+        for colored := 0; colored < n; colored++ {
+            u := -1
+            for v := 0; v < n; v++ {
+                if color[v] >= 0 {
+                    continue
+                }
+                if u < 0 || len(saturation[v]) > len(saturation[u]) ||
+                    (len(saturation[v]) == len(saturation[u]) && degree[v] > degree[u]) {
+                    u = v
+                }
+            }
+
+            color[u] = pick(u)
+            for _, v := range adj[u] {
+                saturation[v][color[u]] = true
+            }
+        }
+
+    default:
+        return Coloring{}, errors.New("Unknown coloring strategy")
+    }
+
+    result := Coloring{Color: make(map[*Node]int, n)}
+    for i, node := range g.nodes {
+        result.Color[node] = color[i]
+        if color[i]+1 > result.Count {
+            result.Count = color[i] + 1
+        }
+        if len(property) > 0 {
+            if err := node.AddProperty(property, strconv.Itoa(color[i])); err != nil {
+                return Coloring{}, err
+            }
+        }
+    }
+
+    return result, nil
+}
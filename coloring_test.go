@@ -0,0 +1,64 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "strconv"
+    "testing"
+)
+
+func TestColor(t *testing.T) {
+
+    // the crown graph links every even node i to the odd nodes other
+    // than i+1; it is bipartite, but coloring in order takes 4 colors
+    crown := func() *Graph {
+        g := NewGraph("crown")
+        for i := 0; i < 8; i++ {
+            g.AddNode(strconv.Itoa(i), strconv.Itoa(i))
+        }
+        for i := 0; i < 8; i += 2 {
+            for j := 1; j < 8; j += 2 {
+                if j != i+1 {
+                    g.AddEdge("e"+strconv.Itoa(i)+strconv.Itoa(j), "link", 1, g.nodes[i], g.nodes[j])
+                }
+            }
+        }
+        return g
+    }
+
+    tests := []struct {
+        name      string
+        g         *Graph
+        strategy  ColoringStrategy
+        want      int
+    }{
+        {"crown in order", crown(), ColorInOrder, 4},
+        {"crown DSatur", crown(), ColorDSatur, 2},
+        {"five cycle DSatur", edgeGraph(t, "a b", "b c", "c d", "d e", "e a"), ColorDSatur, 3},
+        {"wheel Welsh-Powell", edgeGraph(t, "h a", "h b", "h c", "h d", "a b", "b c", "c d", "d a"), ColorWelshPowell, 3},
+        {"self loop", edgeGraph(t, "a a", "a b"), ColorInOrder, 2},
+    }
+
+    for _, test := range tests {
+        c, err := test.g.Color(test.strategy, "color")
+        if err != nil {
+            t.Fatalf("%s: %v", test.name, err)
+        }
+        if c.Count != test.want {
+            t.Errorf("%s: %d colors, want %d", test.name, c.Count, test.want)
+        }
+        for _, e := range test.g.edges() {
+            if e.ParentNode != e.ChildNode && c.Color[e.ParentNode] == c.Color[e.ChildNode] {
+                t.Errorf("%s: %s and %s share a color", test.name, e.ParentNode.GetProperty("id"), e.ChildNode.GetProperty("id"))
+            }
+        }
+        for _, n := range test.g.nodes {
+            if n.GetProperty("color") != strconv.Itoa(c.Color[n]) {
+                t.Errorf("%s: %s has color property %q, want %d", test.name, n.GetProperty("id"), n.GetProperty("color"), c.Color[n])
+            }
+        }
+    }
+}
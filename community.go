@@ -0,0 +1,331 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math/rand"
+    "sort"
+    "strconv"
+)
+
+// Communities holds the result of a community detection run.
+// Community maps every node of the graph to a community id,
+// ids are numbered from 0 in the order nodes were added.
+type Communities struct {
+    Community   map[*Node]int
+    Count       int
+    Modularity  float64
+}
+
+// weightedAdjacency is an undirected view of the graph used by the
+// community algorithms. A self-loop is stored twice so that node
+// degrees always sum to twice the total edge weight.
+type weightedAdjacency []map[int]float64
+
+// edgeWeight converts the distance of an edge back to its weight.
+// Edges without a distance count with a weight of 1.
+func edgeWeight(e *Edge) float64 {
+    if e.Distance > 0 {
+        return 1 / e.Distance
+    }
+    return 1
+}
+
+func (g *Graph) undirectedAdjacency() weightedAdjacency {
+
+    idx := g.nodeIndexes()
+    adj := make(weightedAdjacency, len(g.nodes))
+    for i := range adj {
+        adj[i] = make(map[int]float64)
+    }
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        w := edgeWeight(edge)
+        adj[u][v] += w
+        adj[v][u] += w
+    }
+
+    return adj
+}
+
+func (adj weightedAdjacency) degrees() (k []float64, m2 float64) {
+    k = make([]float64, len(adj))
+    for u := range adj {
+        for _, w := range adj[u] {
+            k[u] += w
+        }
+        m2 += k[u]
+    }
+    return
+}
+
+// modularity computes the modularity of the given community assignment.
+func (adj weightedAdjacency) modularity(community []int) float64 {
+
+    k, m2 := adj.degrees()
+    if m2 == 0 {
+        return 0
+    }
+
+    in := make(map[int]float64)
+    tot := make(map[int]float64)
+    for u := range adj {
+        tot[community[u]] += k[u]
+        for v, w := range adj[u] {
+            if community[u] == community[v] {
+                in[community[u]] += w
+            }
+        }
+    }
+
+    q := 0.0
+    for c, t := range tot {
+        q += in[c]/m2 - (t/m2)*(t/m2)
+    }
+    return q
+}
+
+// Modularity returns the modularity score of a community assignment,
+// treating the graph as undirected. Every node of the graph must be
+// assigned a community.
+func (g *Graph) Modularity(community map[*Node]int) (float64, error) {
+
+    if g == nil {
+        return 0, errors.New("Graph is empty or nil")
+    }
+
+    assigned := make([]int, len(g.nodes))
+    for i, node := range g.nodes {
+        c, ok := community[node]
+        if !ok {
+            return 0, errors.New("Every node requires a community")
+        }
+        assigned[i] = c
+    }
+
+    return g.undirectedAdjacency().modularity(assigned), nil
+}
+
+// Louvain detects communities by greedy modularity optimization.
+// Edges are treated as undirected and weighted by the inverse of their
+// distance. If property is not empty the community id of each node is
+// written back to the node under that property key, and the graph
+// schema rejecting one fails the detection.
+func (g *Graph) Louvain(property string) (Communities, error) {
+
+    if g == nil {
+        return Communities{}, errors.New("Graph is empty or nil")
+    }
+
+    adj := g.undirectedAdjacency()
+
+    // membership of every original node within the current level
+    community := make([]int, len(g.nodes))
+    for i := range community {
+        community[i] = i
+    }
+
+    for {
+        level, moved := adj.louvainLevel()
+        if !moved {
+            break
+        }
+
+        level, count := renumber(level)
+        for i := range community {
+            community[i] = level[community[i]]
+        }
+
+        adj = adj.aggregate(level, count)
+    }
+
+    return g.communities(community, property)
+}
+
+// louvainLevel moves single nodes between communities until no move
+// improves modularity. It reports whether any node changed community.
+func (adj weightedAdjacency) louvainLevel() ([]int, bool) {
+
+    k, m2 := adj.degrees()
+
+    community := make([]int, len(adj))
+    tot := make([]float64, len(adj))
+    for u := range adj {
+        community[u] = u
+        tot[u] = k[u]
+    }
+
+    if m2 == 0 {
+        return community, false
+    }
+
+    moved := false
+    for improved := true; improved; {
+        improved = false
+
+        for u := range adj {
+            current := community[u]
+
+            // weight from u into each neighbouring community
+            links := make(map[int]float64)
+            for v, w := range adj[u] {
+                if v != u {
+                    links[community[v]] += w
+                }
+            }
+
+            tot[current] -= k[u]
+
+            // visit candidates in a fixed order so runs are repeatable
+            candidates := make([]int, 0, len(links))
+            for c := range links {
+                candidates = append(candidates, c)
+            }
+            sort.Ints(candidates)
+
+            best := current
+            bestGain := links[current] - tot[current]*k[u]/m2
+            for _, c := range candidates {
+                gain := links[c] - tot[c]*k[u]/m2
+                if gain > bestGain {
+                    best = c
+                    bestGain = gain
+                }
+            }
+
+            tot[best] += k[u]
+            community[u] = best
+
+            if best != current {
+                improved = true
+                moved = true
+            }
+        }
+    }
+
+    return community, moved
+}
+
+// aggregate collapses every community into a single node.
+func (adj weightedAdjacency) aggregate(community []int, count int) weightedAdjacency {
+
+    out := make(weightedAdjacency, count)
+    for i := range out {
+        out[i] = make(map[int]float64)
+    }
+
+    for u := range adj {
+        for v, w := range adj[u] {
+            out[community[u]][community[v]] += w
+        }
+    }
+
+    return out
+}
+
+// LabelPropagation detects communities by asynchronous label propagation.
+// Nodes are visited in a random order generated from seed and adopt the
+// label carrying the most edge weight among their neighbours, until every
+// node already holds such a label. If property is not empty the community
+// id of each node is written back as Louvain does.
+func (g *Graph) LabelPropagation(seed int64, property string) (Communities, error) {
+
+    if g == nil {
+        return Communities{}, errors.New("Graph is empty or nil")
+    }
+
+    adj := g.undirectedAdjacency()
+    r := rand.New(rand.NewSource(seed))
+
+    label := make([]int, len(adj))
+    for i := range label {
+        label[i] = i
+    }
+
+    for changed := true; changed; {
+        changed = false
+
+        for _, u := range r.Perm(len(adj)) {
+            weights := make(map[int]float64)
+            for v, w := range adj[u] {
+                if v != u {
+                    weights[label[v]] += w
+                }
+            }
+            if len(weights) == 0 {
+                continue
+            }
+
+            best := 0.0
+            for _, w := range weights {
+                if w > best {
+                    best = w
+                }
+            }
+
+            // keep the current label if it is among the best, otherwise
+            // pick one of the best labels at random
+            if weights[label[u]] == best {
+                continue
+            }
+            candidates := make([]int, 0)
+            for l := range label {
+                if weights[l] == best {
+                    candidates = append(candidates, l)
+                }
+            }
+            label[u] = candidates[r.Intn(len(candidates))]
+            changed = true
+        }
+    }
+
+    return g.communities(label, property)
+}
+
+// renumber maps arbitrary community ids to 0..count-1 in order of
+// first appearance.
+func renumber(community []int) ([]int, int) {
+
+    ids := make(map[int]int)
+    out := make([]int, len(community))
+    for i, c := range community {
+        id, ok := ids[c]
+        if !ok {
+            id = len(ids)
+            ids[c] = id
+        }
+        out[i] = id
+    }
+
+    return out, len(ids)
+}
+
+// communities builds the result, failing with the first schema error
+// when writing the community ids back.
+func (g *Graph) communities(community []int, property string) (Communities, error) {
+
+    community, count := renumber(community)
+
+    result := Communities{
+        Community:  make(map[*Node]int, len(g.nodes)),
+        Count:      count,
+        Modularity: g.undirectedAdjacency().modularity(community),
+    }
+
+    for i, node := range g.nodes {
+        result.Community[node] = community[i]
+        if len(property) > 0 {
+            if err := node.AddProperty(property, strconv.Itoa(community[i])); err != nil {
+                return Communities{}, err
+            }
+        }
+    }
+
+    return result, nil
+}
@@ -0,0 +1,113 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "math"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// edgeGraph builds a graph from edges written as "parent child" or
+// "parent child distance". Nodes are created on first use with their id
+// as name, and edges are numbered e0, e1 ...
+func edgeGraph(t *testing.T, edges ...string) *Graph {
+
+    g := NewGraph("test")
+    node := func(id string) *Node {
+        if n, ok := g.GetNodeById(id); ok {
+            return n
+        }
+        n, err := g.AddNode(id, id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return n
+    }
+
+    for i, edge := range edges {
+        f := strings.Fields(edge)
+        distance := 1.0
+        if len(f) == 3 {
+            var err error
+            if distance, err = strconv.ParseFloat(f[2], 64); err != nil {
+                t.Fatal(err)
+            }
+        }
+        e := NewEdge()
+        e.AddProperty("id", "e"+strconv.Itoa(i))
+        e.AddProperty("name", "link")
+        e.SetDistance(distance)
+        e.kind = "link"
+        if err := e.Link(node(f[0]), node(f[1])); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return g
+}
+
+// nodeNames lists the names of nodes in order.
+func nodeNames(nodes []*Node) string {
+    names := make([]string, len(nodes))
+    for i, n := range nodes {
+        names[i] = n.GetProperty("name")
+    }
+    return strings.Join(names, " ")
+}
+
+// twoTriangles is two triangles joined by the edge c d, whose best split
+// has modularity 2 * (3/7 - (7/14)^2) = 5/14.
+func twoTriangles(t *testing.T) *Graph {
+    return edgeGraph(t, "a b", "b c", "c a", "d e", "e f", "f d", "c d")
+}
+
+func TestCommunities(t *testing.T) {
+
+    g := twoTriangles(t)
+    louvain, err := g.Louvain("community")
+    if err != nil {
+        t.Fatal(err)
+    }
+    spread, err := g.LabelPropagation(1, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, test := range []struct {
+        name  string
+        c     Communities
+    }{{"Louvain", louvain}, {"LabelPropagation", spread}} {
+        if test.c.Count != 2 {
+            t.Errorf("%s: %d communities, want 2", test.name, test.c.Count)
+        }
+        if math.Abs(test.c.Modularity-5.0/14) > 1e-9 {
+            t.Errorf("%s: modularity %v, want 5/14", test.name, test.c.Modularity)
+        }
+        for i, n := range g.nodes {
+            if want := test.c.Community[g.nodes[i/3*3]]; test.c.Community[n] != want {
+                t.Errorf("%s: %s is in community %d, want %d", test.name, n.GetProperty("name"), test.c.Community[n], want)
+            }
+        }
+    }
+
+    if got := g.nodes[5].GetProperty("community"); got != strconv.Itoa(louvain.Community[g.nodes[5]]) {
+        t.Errorf("community property %q, want %d", got, louvain.Community[g.nodes[5]])
+    }
+
+    all := make(map[*Node]int)
+    for _, n := range g.nodes {
+        all[n] = 0
+    }
+    if q, err := g.Modularity(all); err != nil || q != 0 {
+        t.Errorf("one community has modularity %v, %v, want 0", q, err)
+    }
+    delete(all, g.nodes[0])
+    if _, err := g.Modularity(all); err == nil {
+        t.Errorf("modularity without a community for every node did not fail")
+    }
+}
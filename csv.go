@@ -0,0 +1,332 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "encoding/csv"
+    "errors"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// CSVOptions configures reading and writing node and edge tables.
+//
+// The node table has a header row and one row per node. Every column
+// becomes a node property named after its header, the "id" column is
+// required and ids must be unique. The edge table has a header row and
+// one row per edge with the parent and child node ids, an optional
+// distance column and any other column as edge properties. Empty cells
+// are left out.
+type CSVOptions struct {
+    // Comma is the field delimiter, ',' if zero.
+    Comma           rune
+    // Columns renames headers to property keys when reading, and
+    // property keys back to headers when writing.
+    Columns         map[string]string
+    // ParentColumn, ChildColumn and DistanceColumn name the edge columns,
+    // "source", "target" and "distance" if empty.
+    ParentColumn    string
+    ChildColumn     string
+    DistanceColumn  string
+    // WeightColumn, if set and no distance column is present, names a
+    // column holding the edge weight instead.
+    WeightColumn    string
+    // SkipErrors skips bad rows instead of stopping at the first one.
+    SkipErrors      bool
+}
+
+// CSVError reports a bad row of a node or edge table.
+type CSVError struct {
+    Table  string
+    Line   int
+    Err    error
+}
+
+func (e *CSVError) Error() string {
+    return e.Table + " line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// CSVErrors lists the rows skipped by ReadCSV.
+type CSVErrors []*CSVError
+
+func (e CSVErrors) Error() string {
+    parts := make([]string, len(e))
+    for i, err := range e {
+        parts[i] = err.Error()
+    }
+    return strings.Join(parts, "\n")
+}
+
+func (o CSVOptions) column(name string, fallback string) string {
+    if len(name) > 0 {
+        return name
+    }
+    return fallback
+}
+
+// rename maps a header to its property key.
+func (o CSVOptions) rename(header string) string {
+    if key, ok := o.Columns[header]; ok {
+        return key
+    }
+    return header
+}
+
+// header maps a property key back to its header.
+func (o CSVOptions) header(key string) string {
+    for header, k := range o.Columns {
+        if k == key {
+            return header
+        }
+    }
+    return key
+}
+
+// ReadCSV builds a graph from a node table and an edge table, reading
+// both one row at a time. Bad rows stop the read with a *CSVError, or
+// with SkipErrors are left out and returned together as CSVErrors along
+// with the graph. A "labels" column such as ":Person:Place" sets the
+// node labels.
+func ReadCSV(nodes io.Reader, edges io.Reader, opts CSVOptions) (*Graph, error) {
+
+    g := NewGraph("")
+    byID := make(map[string]*Node)
+    skipped := make(CSVErrors, 0)
+
+    // fail reports a bad row and returns true if reading should stop
+    fail := func(table string, line int, err error) (bool, error) {
+        e := &CSVError{Table: table, Line: line, Err: err}
+        if !opts.SkipErrors {
+            return true, e
+        }
+        skipped = append(skipped, e)
+        return false, nil
+    }
+
+    err := readCSVTable(nodes, "nodes", opts, func(row map[string]string, line int) (bool, error) {
+        id := row["id"]
+        if len(id) == 0 {
+            return fail("nodes", line, errors.New("Id required"))
+        }
+        if _, ok := byID[id]; ok {
+            return fail("nodes", line, errors.New("Duplicate id "+strconv.Quote(id)))
+        }
+
+        n := NewNode()
+        for key, value := range row {
+            n.Properties[key] = value
+        }
+        n.readLabels()
+        byID[id] = n
+        g.insertNode(n)
+        return false, nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    parentColumn := opts.column(opts.ParentColumn, "source")
+    childColumn := opts.column(opts.ChildColumn, "target")
+    distanceColumn := opts.column(opts.DistanceColumn, "distance")
+
+    err = readCSVTable(edges, "edges", opts, func(row map[string]string, line int) (bool, error) {
+        parent, ok := byID[row[parentColumn]]
+        if !ok {
+            return fail("edges", line, errors.New("Unknown parent node "+strconv.Quote(row[parentColumn])))
+        }
+        child, ok := byID[row[childColumn]]
+        if !ok {
+            return fail("edges", line, errors.New("Unknown child node "+strconv.Quote(row[childColumn])))
+        }
+
+        e := NewEdge()
+        for key, value := range row {
+            e.Properties[key] = value
+        }
+        delete(e.Properties, parentColumn)
+        delete(e.Properties, childColumn)
+
+        if value, ok := row[distanceColumn]; ok {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return fail("edges", line, err)
+            }
+            e.SetDistance(v)
+            delete(e.Properties, distanceColumn)
+        } else if value, ok := row[opts.WeightColumn]; ok && len(opts.WeightColumn) > 0 {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return fail("edges", line, err)
+            }
+            e.SetWeight(v)
+        }
+        e.kind = e.Properties["name"]
+
+        return false, e.Link(parent, child)
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if len(skipped) > 0 {
+        return g, skipped
+    }
+    return g, nil
+}
+
+// readCSVTable calls row for every data row with its non empty cells
+// keyed by property, until row returns true or an error.
+func readCSVTable(r io.Reader, table string, opts CSVOptions, row func(map[string]string, int) (bool, error)) error {
+
+    cr := csv.NewReader(r)
+    if opts.Comma != 0 {
+        cr.Comma = opts.Comma
+    }
+    cr.FieldsPerRecord = -1
+
+    header, err := cr.Read()
+    if err == io.EOF {
+        return nil
+    }
+    if err != nil {
+        return &CSVError{Table: table, Line: 1, Err: err}
+    }
+    keys := make([]string, len(header))
+    for i, h := range header {
+        keys[i] = opts.rename(strings.TrimSpace(h))
+    }
+
+    for {
+        record, err := cr.Read()
+        if err == io.EOF {
+            return nil
+        }
+        if pe, ok := err.(*csv.ParseError); ok {
+            return &CSVError{Table: table, Line: pe.Line, Err: pe.Err}
+        }
+        if err != nil {
+            return err
+        }
+        line, _ := cr.FieldPos(0)
+
+        cells := make(map[string]string, len(record))
+        for i, value := range record {
+            if i < len(keys) && len(value) > 0 {
+                cells[keys[i]] = value
+            }
+        }
+
+        stop, err := row(cells, line)
+        if stop || err != nil {
+            return err
+        }
+    }
+}
+
+// WriteCSV writes the graph as a node table and an edge table that
+// ReadCSV reads back. The node table starts with the id and name
+// columns, the edge table with the parent, child and distance columns,
+// followed by every other property in name order, with node labels in
+// the "labels" column as ":Person:Place". Nodes must have unique ids.
+func (g *Graph) WriteCSV(nodes io.Writer, edges io.Writer, opts CSVOptions) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    nodeProps := make([]map[string]string, len(g.nodes))
+    seen := make(map[string]bool)
+    for i, node := range g.nodes {
+        nodeProps[i] = node.fileProperties()
+        id := nodeProps[i]["id"]
+        if len(id) == 0 || seen[id] {
+            return errors.New("Nodes require unique ids")
+        }
+        seen[id] = true
+    }
+
+    edgeList := g.edges()
+    edgeProps := make([]map[string]string, len(edgeList))
+    for i, edge := range edgeList {
+        edgeProps[i] = edge.copyProperties()
+    }
+
+    parentColumn := opts.column(opts.ParentColumn, "source")
+    childColumn := opts.column(opts.ChildColumn, "target")
+    distanceColumn := opts.column(opts.DistanceColumn, "distance")
+
+    nodeKeys := csvKeys(nodeProps, []string{"id", "name"})
+    err := writeCSVTable(nodes, opts, nodeKeys, len(g.nodes), func(i int) map[string]string {
+        return nodeProps[i]
+    })
+    if err != nil {
+        return err
+    }
+
+    edgeKeys := csvKeys(edgeProps, []string{parentColumn, childColumn, distanceColumn})
+    return writeCSVTable(edges, opts, edgeKeys, len(edgeList), func(i int) map[string]string {
+        row := edgeProps[i]
+        row[parentColumn] = edgeList[i].ParentNode.GetProperty("id")
+        row[childColumn] = edgeList[i].ChildNode.GetProperty("id")
+        row[distanceColumn] = strconv.FormatFloat(edgeList[i].Distance, 'g', -1, 64)
+        return row
+    })
+}
+
+// csvKeys returns first followed by every other key found in props in
+// name order.
+func csvKeys(props []map[string]string, first []string) []string {
+
+    seen := make(map[string]bool)
+    for _, key := range first {
+        seen[key] = true
+    }
+
+    rest := make([]string, 0)
+    for _, p := range props {
+        for key := range p {
+            if !seen[key] {
+                seen[key] = true
+                rest = append(rest, key)
+            }
+        }
+    }
+    sort.Strings(rest)
+
+    return append(append([]string{}, first...), rest...)
+}
+
+func writeCSVTable(w io.Writer, opts CSVOptions, keys []string, rows int, row func(int) map[string]string) error {
+
+    cw := csv.NewWriter(w)
+    if opts.Comma != 0 {
+        cw.Comma = opts.Comma
+    }
+
+    header := make([]string, len(keys))
+    for i, key := range keys {
+        header[i] = opts.header(key)
+    }
+    if err := cw.Write(header); err != nil {
+        return err
+    }
+
+    record := make([]string, len(keys))
+    for i := 0; i < rows; i++ {
+        values := row(i)
+        for j, key := range keys {
+            record[j] = values[key]
+        }
+        if err := cw.Write(record); err != nil {
+            return err
+        }
+    }
+
+    cw.Flush()
+    return cw.Error()
+}
@@ -0,0 +1,120 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+
+    g := NewGraph("g")
+    a, _ := g.AddNode("a", "Smith, Ann", "Person")
+    b, _ := g.AddNode("b", "Bob")
+    b.AddProperty("age", "7")
+    g.AddEdge("ab", "knows", 0.5, a, b)
+
+    var nodes, edges bytes.Buffer
+    if err := g.WriteCSV(&nodes, &edges, CSVOptions{}); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := nodes.String(), "id,name,age,labels\na,\"Smith, Ann\",,:Person\nb,Bob,7,\n"; got != want {
+        t.Errorf("nodes\n%s\nwant\n%s", got, want)
+    }
+    if got, want := edges.String(), "source,target,distance,id,name\na,b,2,ab,knows\n"; got != want {
+        t.Errorf("edges\n%s\nwant\n%s", got, want)
+    }
+
+    nodes.Reset()
+    edges.Reset()
+    opts := CSVOptions{Comma: ';', Columns: map[string]string{"label": "name"}, ParentColumn: "from", ChildColumn: "to"}
+    if err := g.WriteCSV(&nodes, &edges, opts); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := nodes.String(), "id;label;age;labels\na;Smith, Ann;;:Person\nb;Bob;7;\n"; got != want {
+        t.Errorf("renamed nodes\n%s\nwant\n%s", got, want)
+    }
+    if got, want := edges.String(), "from;to;distance;id;label\na;b;2;ab;knows\n"; got != want {
+        t.Errorf("renamed edges\n%s\nwant\n%s", got, want)
+    }
+
+    g.AddNode("a", "again")
+    if err := g.WriteCSV(&nodes, &edges, CSVOptions{}); err == nil {
+        t.Errorf("writing repeated ids did not fail")
+    }
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+
+    g := exchangeGraph(t)
+    var nodes, edges bytes.Buffer
+    if err := g.WriteCSV(&nodes, &edges, CSVOptions{}); err != nil {
+        t.Fatal(err)
+    }
+    h, err := ReadCSV(&nodes, &edges, CSVOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    sameGraph(t, "CSV", g, h)
+}
+
+func TestReadCSV(t *testing.T) {
+
+    nodes := "ID;label\n1;One\n2;Two\n3;\n"
+    edges := "from;to;w;note\n1;2;4;first\n2;3;;\n"
+    opts := CSVOptions{
+        Comma:         ';',
+        Columns:       map[string]string{"ID": "id", "label": "name"},
+        ParentColumn:  "from",
+        ChildColumn:   "to",
+        WeightColumn:  "w",
+    }
+    g, err := ReadCSV(strings.NewReader(nodes), strings.NewReader(edges), opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    three, _ := g.GetNodeById("3")
+    if len(g.nodes) != 3 || three == nil || three.HasProperty("name") {
+        t.Errorf("%d nodes, want 3 with an unnamed node 3", len(g.nodes))
+    }
+    // the weight column stays a property, empty cells are left out
+    if got, want := edgeList(g), "1 2 0.25 \n2 3 0 "; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        if e.HasProperty("from") || e.HasProperty("to") || (e.ParentNode.GetProperty("id") == "1") != e.HasProperty("note") {
+            t.Errorf("edge %s %s has properties %v", e.ParentNode.GetProperty("id"), e.ChildNode.GetProperty("id"), e.copyProperties())
+        }
+    }
+
+    nodes = "id,name\na,A\n,nameless\na,again\nb,B\n"
+    edges = "source,target,distance\na,b,1\na,c,1\nb,a,far\n"
+    _, err = ReadCSV(strings.NewReader(nodes), strings.NewReader(edges), CSVOptions{})
+    if e, ok := err.(*CSVError); !ok || e.Table != "nodes" || e.Line != 3 {
+        t.Errorf("error %v, want nodes line 3", err)
+    }
+
+    g, err = ReadCSV(strings.NewReader(nodes), strings.NewReader(edges), CSVOptions{SkipErrors: true})
+    skipped, ok := err.(CSVErrors)
+    if !ok || g == nil {
+        t.Fatalf("error %v, want skipped rows", err)
+    }
+    want := "nodes line 3: Id required\nnodes line 4: Duplicate id \"a\"\n" +
+        "edges line 3: Unknown child node \"c\"\nedges line 4: strconv.ParseFloat: parsing \"far\": invalid syntax"
+    if skipped.Error() != want {
+        t.Errorf("skipped\n%s\nwant\n%s", skipped.Error(), want)
+    }
+    if len(g.nodes) != 2 || edgeList(g) != "a b 1 " {
+        t.Errorf("%d nodes and edges %q, want 2 and a b", len(g.nodes), edgeList(g))
+    }
+
+    _, err = ReadCSV(strings.NewReader("id\n\"a\n"), strings.NewReader(""), CSVOptions{})
+    if _, ok := err.(*CSVError); !ok {
+        t.Errorf("error %v, want a *CSVError for a bad quote", err)
+    }
+}
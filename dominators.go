@@ -0,0 +1,308 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+)
+
+// Dominators holds the dominator relation of every node reachable from
+// a root. A node a dominates b when every path from the root to b passes
+// through a. For post-dominators the paths lead from b to the root.
+type Dominators struct {
+    Root      *Node
+    nodes     []*Node
+    index     map[*Node]int
+    idom      []int
+    preds     [][]int
+    frontier  [][]int
+}
+
+// Dominators computes the dominators of every node reachable from root
+// using the Lengauer-Tarjan algorithm.
+func (g *Graph) Dominators(root *Node) (*Dominators, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    succ := g.successors()
+    return g.dominators(root, succ, reverse(succ))
+}
+
+// PostDominators computes the post-dominators of every node that can
+// reach exit, by running Dominators on the reversed graph.
+func (g *Graph) PostDominators(exit *Node) (*Dominators, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    succ := g.successors()
+    return g.dominators(exit, reverse(succ), succ)
+}
+
+// reverse flips the direction of every edge of an adjacency list.
+func reverse(adj [][]int) [][]int {
+    out := make([][]int, len(adj))
+    for u := range adj {
+        for _, v := range adj[u] {
+            out[v] = append(out[v], u)
+        }
+    }
+    return out
+}
+
+func (g *Graph) dominators(root *Node, succ [][]int, pred [][]int) (*Dominators, error) {
+
+    idx := g.nodeIndexes()
+    r, ok := idx[root]
+    if !ok {
+        return nil, errors.New("Root node required")
+    }
+
+    n := len(g.nodes)
+    const none = -1
+
+    dfnum := make([]int, n)
+    parent := make([]int, n)
+    semi := make([]int, n)
+    ancestor := make([]int, n)
+    label := make([]int, n)
+    idom := make([]int, n)
+    bucket := make([][]int, n)
+    vertex := make([]int, 0, n)
+
+    for v := 0; v < n; v++ {
+        dfnum[v] = none
+        ancestor[v] = none
+        idom[v] = none
+        label[v] = v
+    }
+
+    var dfs func(v int)
+    dfs = func(v int) {
+        dfnum[v] = len(vertex)
+        semi[v] = dfnum[v]
+        vertex = append(vertex, v)
+        for _, w := range succ[v] {
+            if dfnum[w] == none {
+                parent[w] = v
+                dfs(w)
+            }
+        }
+    }
+    dfs(r)
+
+    var compress func(v int)
+    compress = func(v int) {
+        a := ancestor[v]
+        if ancestor[a] == none {
+            return
+        }
+        compress(a)
+        if semi[label[a]] < semi[label[v]] {
+            label[v] = label[a]
+        }
+        ancestor[v] = ancestor[a]
+    }
+
+    eval := func(v int) int {
+        if ancestor[v] == none {
+            return v
+        }
+        compress(v)
+        return label[v]
+    }
+
+    for i := len(vertex) - 1; i > 0; i-- {
+        w := vertex[i]
+
+        for _, v := range pred[w] {
+            if dfnum[v] == none {
+                continue
+            }
+            if u := eval(v); semi[u] < semi[w] {
+                semi[w] = semi[u]
+            }
+        }
+
+        s := vertex[semi[w]]
+        bucket[s] = append(bucket[s], w)
+        ancestor[w] = parent[w]
+
+        p := parent[w]
+        for _, v := range bucket[p] {
+            if u := eval(v); semi[u] < semi[v] {
+                idom[v] = u
+            } else {
+                idom[v] = p
+            }
+        }
+        bucket[p] = nil
+    }
+
+    for i := 1; i < len(vertex); i++ {
+        w := vertex[i]
+        if idom[w] != vertex[semi[w]] {
+            idom[w] = idom[idom[w]]
+        }
+    }
+
+    nodes := make([]*Node, n)
+    copy(nodes, g.nodes)
+
+    d := &Dominators{
+        Root:   root,
+        nodes:  nodes,
+        index:  idx,
+        idom:   idom,
+        preds:  pred,
+    }
+    d.frontier = d.frontiers(dfnum)
+
+    return d, nil
+}
+
+// frontiers computes the dominance frontier of every reachable node.
+func (d *Dominators) frontiers(dfnum []int) [][]int {
+
+    out := make([][]int, len(d.nodes))
+    seen := make([]map[int]bool, len(d.nodes))
+
+    for b := range d.nodes {
+        if dfnum[b] < 0 {
+            continue
+        }
+
+        reachable := make([]int, 0)
+        for _, p := range d.preds[b] {
+            if dfnum[p] >= 0 {
+                reachable = append(reachable, p)
+            }
+        }
+        if len(reachable) < 2 {
+            continue
+        }
+
+        for _, runner := range reachable {
+            for runner >= 0 && runner != d.idom[b] {
+                if seen[runner] == nil {
+                    seen[runner] = make(map[int]bool)
+                }
+                if !seen[runner][b] {
+                    seen[runner][b] = true
+                    out[runner] = append(out[runner], b)
+                }
+                runner = d.idom[runner]
+            }
+        }
+    }
+
+    return out
+}
+
+// Immediate returns the closest strict dominator of a node, or false
+// for the root and nodes that are not reachable.
+func (d *Dominators) Immediate(n *Node) (*Node, bool) {
+
+    if d == nil {
+        return nil, false
+    }
+
+    i, ok := d.index[n]
+    if !ok || d.idom[i] < 0 {
+        return nil, false
+    }
+
+    return d.nodes[d.idom[i]], true
+}
+
+// Dominates returns true if a dominates b. Every reachable node
+// dominates itself.
+func (d *Dominators) Dominates(a *Node, b *Node) bool {
+
+    if d == nil {
+        return false
+    }
+
+    i, ok := d.index[a]
+    if !ok {
+        return false
+    }
+
+    j, ok := d.index[b]
+    if !ok {
+        return false
+    }
+
+    for ; j >= 0; j = d.idom[j] {
+        if i == j {
+            return i == d.index[d.Root] || d.idom[i] >= 0
+        }
+    }
+
+    return false
+}
+
+// Frontier returns the dominance frontier of a node, the nodes where
+// its dominance ends.
+func (d *Dominators) Frontier(n *Node) []*Node {
+
+    out := make([]*Node, 0)
+
+    if d == nil {
+        return out
+    }
+
+    i, ok := d.index[n]
+    if !ok {
+        return out
+    }
+
+    for _, b := range d.frontier[i] {
+        out = append(out, d.nodes[b])
+    }
+
+    return out
+}
+
+// Tree returns the dominator tree as a new graph holding a copy of every
+// reachable node, linked from its immediate dominator. Tree edges are
+// named "dominates", have a weight of 1 and the id
+// "<dominator id>-><node id>".
+func (d *Dominators) Tree() (*Graph, error) {
+
+    if d == nil {
+        return nil, errors.New("Dominators are nil")
+    }
+
+    tree := NewGraph("")
+    copies := make([]*Node, len(d.nodes))
+
+    for i, node := range d.nodes {
+        if i != d.index[d.Root] && d.idom[i] < 0 {
+            continue
+        }
+        n, err := copyNode(tree, node)
+        if err != nil {
+            return nil, err
+        }
+        copies[i] = n
+    }
+
+    for i, p := range d.idom {
+        if p < 0 {
+            continue
+        }
+        id := copies[p].GetProperty("id") + "->" + copies[i].GetProperty("id")
+        if err := tree.AddEdge(id, "dominates", 1, copies[p], copies[i]); err != nil {
+            return nil, err
+        }
+    }
+
+    return tree, nil
+}
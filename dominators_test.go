@@ -0,0 +1,98 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "testing"
+)
+
+func TestDominators(t *testing.T) {
+
+    // the flow graph of figure 1 in Lengauer and Tarjan, "A fast
+    // algorithm for finding dominators in a flowgraph", 1979
+    g := edgeGraph(t,
+        "R A", "R B", "R C", "A D", "B A", "B D", "B E", "C F", "C G",
+        "D L", "E H", "F I", "G I", "G J", "H E", "H K", "I K", "J I",
+        "K I", "K R", "L H")
+    root, _ := g.GetNodeById("R")
+    dom, err := g.Dominators(root)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want := map[string]string{
+        "A": "R", "B": "R", "C": "R", "D": "R", "E": "R", "F": "C",
+        "G": "C", "H": "R", "I": "R", "J": "G", "K": "R", "L": "D",
+    }
+    for _, n := range g.nodes {
+        id := n.GetProperty("id")
+        idom, ok := dom.Immediate(n)
+        if id == "R" {
+            if ok {
+                t.Errorf("root has immediate dominator %s", idom.GetProperty("id"))
+            }
+            continue
+        }
+        if !ok || idom.GetProperty("id") != want[id] {
+            t.Errorf("immediate dominator of %s is %v, want %s", id, nodeNames([]*Node{idom}), want[id])
+        }
+    }
+
+    c, _ := g.GetNodeById("C")
+    j, _ := g.GetNodeById("J")
+    i, _ := g.GetNodeById("I")
+    if !dom.Dominates(c, j) || !dom.Dominates(root, j) || !dom.Dominates(j, j) || dom.Dominates(c, i) {
+        t.Errorf("C dominates J and not I, R dominates J and J itself")
+    }
+
+    tree, err := dom.Tree()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(tree.nodes) != 13 || len(tree.edges()) != 12 {
+        t.Errorf("tree has %d nodes and %d edges, want 13 and 12", len(tree.nodes), len(tree.edges()))
+    }
+    if _, ok := tree.GetNodeById("C->J"); ok {
+        t.Errorf("tree links C to J, whose immediate dominator is G")
+    }
+}
+
+func TestDominanceFrontier(t *testing.T) {
+
+    // a loop around a diamond, with z not reachable from the entry
+    g := edgeGraph(t, "entry b1", "b1 b2", "b1 b3", "b2 b4", "b3 b4", "b4 b1", "b4 exit", "z b1")
+    entry, _ := g.GetNodeById("entry")
+    dom, err := g.Dominators(entry)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want := map[string]string{"entry": "", "b1": "b1", "b2": "b4", "b3": "b4", "b4": "b1", "exit": "", "z": ""}
+    for _, n := range g.nodes {
+        if got := nodeNames(dom.Frontier(n)); got != want[n.GetProperty("id")] {
+            t.Errorf("frontier of %s is %q, want %q", n.GetProperty("id"), got, want[n.GetProperty("id")])
+        }
+    }
+    z, _ := g.GetNodeById("z")
+    if _, ok := dom.Immediate(z); ok {
+        t.Errorf("unreachable z has an immediate dominator")
+    }
+
+    exit, _ := g.GetNodeById("exit")
+    post, err := g.PostDominators(exit)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want = map[string]string{"entry": "b1", "b1": "b4", "b2": "b4", "b3": "b4", "b4": "exit", "z": "b1"}
+    for _, n := range g.nodes {
+        if n == exit {
+            continue
+        }
+        if p, ok := post.Immediate(n); !ok || p.GetProperty("id") != want[n.GetProperty("id")] {
+            t.Errorf("immediate post-dominator of %s is %v, want %s", n.GetProperty("id"), nodeNames([]*Node{p}), want[n.GetProperty("id")])
+        }
+    }
+}
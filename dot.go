@@ -0,0 +1,620 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// DOTOptions configures WriteDOT.
+type DOTOptions struct {
+    // NodeAttributes and EdgeAttributes map property keys to the DOT
+    // attribute they are written as, for example "group" to "color".
+    NodeAttributes  map[string]string
+    EdgeAttributes  map[string]string
+    // HideDistance leaves the distance out of edge labels.
+    HideDistance    bool
+    // Highlight draws the edges and nodes of a path in HighlightColor,
+    // red if empty.
+    Highlight       *Path
+    HighlightColor  string
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+    r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+    return `"` + r.Replace(s) + `"`
+}
+
+// dotAttributes formats an attribute list in key order.
+func dotAttributes(attrs map[string]string) string {
+
+    if len(attrs) == 0 {
+        return ""
+    }
+
+    keys := make([]string, 0, len(attrs))
+    for key := range attrs {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    parts := make([]string, len(keys))
+    for i, key := range keys {
+        parts[i] = dotQuote(key) + "=" + dotQuote(attrs[key])
+    }
+
+    return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. Nodes are
+// labelled with their "name" property and edges with their "name"
+// property followed by their distance, which is also written as the
+// "distance" attribute. Node labels are written as the "labels"
+// attribute, ":Person:Place". Nodes are identified by their "id" property, or
+// by their position if ids are missing or repeated. Edges with the
+// property "directed" set to "false" are drawn without an arrow head.
+func (g *Graph) WriteDOT(w io.Writer, opts DOTOptions) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    names := make(map[*Node]string, len(g.nodes))
+    for i, id := range g.nodeIDs() {
+        names[g.nodes[i]] = id
+    }
+
+    color := opts.HighlightColor
+    if len(color) == 0 {
+        color = "red"
+    }
+    highlighted := make(map[[2]*Node]bool)
+    onPath := make(map[*Node]bool)
+    if opts.Highlight != nil {
+        for i := range opts.Highlight.Path {
+            e := &opts.Highlight.Path[i]
+            highlighted[[2]*Node{e.ParentNode, e.ChildNode}] = true
+            onPath[e.ParentNode] = true
+            onPath[e.ChildNode] = true
+        }
+    }
+
+    bw := bufio.NewWriter(w)
+    bw.WriteString("digraph ")
+    if len(g.id) > 0 {
+        bw.WriteString(dotQuote(g.id) + " ")
+    }
+    bw.WriteString("{\n")
+
+    for _, node := range g.nodes {
+        props := node.fileProperties()
+        attrs := make(map[string]string)
+        if name, ok := props["name"]; ok {
+            attrs["label"] = name
+        }
+        if labels, ok := props["labels"]; ok {
+            attrs["labels"] = labels
+        }
+        for key, attr := range opts.NodeAttributes {
+            if value, ok := props[key]; ok {
+                attrs[attr] = value
+            }
+        }
+        if onPath[node] {
+            attrs["color"] = color
+        }
+        bw.WriteString("    " + dotQuote(names[node]) + dotAttributes(attrs) + ";\n")
+    }
+
+    for _, edge := range g.edges() {
+        props := edge.copyProperties()
+        distance := strconv.FormatFloat(edge.Distance, 'g', -1, 64)
+        attrs := map[string]string{"distance": distance}
+
+        label := props["name"]
+        if !opts.HideDistance {
+            label = strings.TrimSpace(label + " (" + distance + ")")
+        }
+        if len(label) > 0 {
+            attrs["label"] = label
+        }
+        if props["directed"] == "false" {
+            attrs["dir"] = "none"
+        }
+        for key, attr := range opts.EdgeAttributes {
+            if value, ok := props[key]; ok {
+                attrs[attr] = value
+            }
+        }
+        if highlighted[[2]*Node{edge.ParentNode, edge.ChildNode}] {
+            attrs["color"] = color
+            attrs["penwidth"] = "2"
+        }
+
+        bw.WriteString("    " + dotQuote(names[edge.ParentNode]) + " -> " +
+            dotQuote(names[edge.ChildNode]) + dotAttributes(attrs) + ";\n")
+    }
+
+    bw.WriteString("}\n")
+    return bw.Flush()
+}
+
+// ReadDOT builds a graph from a file in the Graphviz DOT language. Node
+// identifiers become the "id" property and every attribute becomes a
+// property of the same name, except that a "label" without a "name"
+// attribute becomes the "name" property. The edge "distance" attribute
+// sets Edge.Distance, otherwise a "weight" attribute sets the edge
+// weight; a distance WriteDOT appended to a label is removed again.
+// Edges of an undirected graph get the property "directed" set to
+// "false", and a "labels" attribute such as ":Person:Place" sets the
+// node labels. Ports and graph attributes are ignored.
+func ReadDOT(r io.Reader) (*Graph, error) {
+
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    tokens, err := dotTokens(string(data))
+    if err != nil {
+        return nil, err
+    }
+
+    p := &dotParser{tokens: tokens, nodes: make(map[string]*Node)}
+    if err := p.parse(); err != nil {
+        return nil, err
+    }
+
+    // attributes may be set by several statements, so labels come last
+    for _, n := range p.g.nodes {
+        n.readLabels()
+    }
+
+    return p.g, nil
+}
+
+// dotToken is a DOT identifier or punctuation. Quoted identifiers are
+// marked so that they are never taken for keywords.
+type dotToken struct {
+    text    string
+    id      bool
+    quoted  bool
+}
+
+func dotTokens(src string) ([]dotToken, error) {
+
+    out := make([]dotToken, 0)
+    lineStart := true
+
+    for i := 0; i < len(src); {
+        c := src[i]
+
+        switch {
+        case c == '\n':
+            lineStart = true
+            i++
+            continue
+        case c == ' ' || c == '\t' || c == '\r':
+            i++
+            continue
+        case c == '#' && lineStart:
+            for i < len(src) && src[i] != '\n' {
+                i++
+            }
+            continue
+        case strings.HasPrefix(src[i:], "//"):
+            for i < len(src) && src[i] != '\n' {
+                i++
+            }
+            continue
+        case strings.HasPrefix(src[i:], "/*"):
+            end := strings.Index(src[i+2:], "*/")
+            if end < 0 {
+                return nil, errors.New("Unterminated comment")
+            }
+            i += end + 4
+            continue
+        }
+        lineStart = false
+
+        switch {
+        case strings.HasPrefix(src[i:], "->") || strings.HasPrefix(src[i:], "--"):
+            out = append(out, dotToken{text: src[i : i+2]})
+            i += 2
+
+        case strings.ContainsRune("{}[];,=:", rune(c)):
+            out = append(out, dotToken{text: string(c)})
+            i++
+
+        case c == '"':
+            var sb strings.Builder
+            i++
+            for ; i < len(src) && src[i] != '"'; i++ {
+                if src[i] == '\\' && i+1 < len(src) {
+                    switch src[i+1] {
+                    case '"', '\\':
+                        sb.WriteByte(src[i+1])
+                        i++
+                        continue
+                    case 'n':
+                        sb.WriteByte('\n')
+                        i++
+                        continue
+                    case '\n':
+                        i++
+                        continue
+                    }
+                }
+                sb.WriteByte(src[i])
+            }
+            if i >= len(src) {
+                return nil, errors.New("Unterminated string")
+            }
+            i++
+            text := sb.String()
+            // concatenate "a" + "b"
+            if n := len(out); n >= 2 && out[n-1].text == "+" && out[n-2].quoted {
+                out[n-2].text += text
+                out = out[:n-1]
+                continue
+            }
+            out = append(out, dotToken{text: text, id: true, quoted: true})
+
+        case c == '<':
+            depth := 0
+            start := i
+            for ; i < len(src); i++ {
+                if src[i] == '<' {
+                    depth++
+                } else if src[i] == '>' {
+                    depth--
+                    if depth == 0 {
+                        break
+                    }
+                }
+            }
+            if i >= len(src) {
+                return nil, errors.New("Unterminated HTML string")
+            }
+            out = append(out, dotToken{text: src[start+1 : i], id: true, quoted: true})
+            i++
+
+        case c == '+':
+            out = append(out, dotToken{text: "+"})
+            i++
+
+        default:
+            start := i
+            for i < len(src) && !strings.ContainsRune(" \t\r\n{}[];,=:\"<+#", rune(src[i])) &&
+                !strings.HasPrefix(src[i:], "->") && !strings.HasPrefix(src[i:], "--") &&
+                !strings.HasPrefix(src[i:], "//") && !strings.HasPrefix(src[i:], "/*") {
+                i++
+            }
+            if i == start {
+                return nil, errors.New("Unexpected character " + strconv.Quote(string(c)))
+            }
+            out = append(out, dotToken{text: src[start:i], id: true})
+        }
+    }
+
+    return out, nil
+}
+
+// dotParser builds a graph from DOT tokens.
+type dotParser struct {
+    tokens    []dotToken
+    pos       int
+    g         *Graph
+    directed  bool
+    nodes     map[string]*Node
+}
+
+// dotScope holds the default attributes of a graph or subgraph.
+type dotScope struct {
+    node  map[string]string
+    edge  map[string]string
+}
+
+func (s dotScope) child() dotScope {
+    c := dotScope{node: make(map[string]string), edge: make(map[string]string)}
+    for k, v := range s.node {
+        c.node[k] = v
+    }
+    for k, v := range s.edge {
+        c.edge[k] = v
+    }
+    return c
+}
+
+func (p *dotParser) peek() dotToken {
+    if p.pos < len(p.tokens) {
+        return p.tokens[p.pos]
+    }
+    return dotToken{}
+}
+
+func (p *dotParser) next() dotToken {
+    t := p.peek()
+    p.pos++
+    return t
+}
+
+// keyword returns true if the next token is the unquoted keyword kw.
+func (p *dotParser) keyword(kw string) bool {
+    t := p.peek()
+    return t.id && !t.quoted && strings.EqualFold(t.text, kw)
+}
+
+func (p *dotParser) expect(text string) error {
+    if t := p.next(); t.id || t.text != text {
+        return errors.New("Expected " + strconv.Quote(text) + " but found " + strconv.Quote(t.text))
+    }
+    return nil
+}
+
+func (p *dotParser) parse() error {
+
+    if p.keyword("strict") {
+        p.next()
+    }
+
+    switch {
+    case p.keyword("digraph"):
+        p.directed = true
+    case p.keyword("graph"):
+        p.directed = false
+    default:
+        return errors.New("Expected graph or digraph")
+    }
+    p.next()
+
+    id := ""
+    if p.peek().id {
+        id = p.next().text
+    }
+    p.g = NewGraph(id)
+
+    if err := p.expect("{"); err != nil {
+        return err
+    }
+
+    scope := dotScope{}.child()
+    if _, err := p.statements(scope); err != nil {
+        return err
+    }
+
+    return p.expect("}")
+}
+
+// statements parses statements up to a closing brace and returns the
+// nodes they mention.
+func (p *dotParser) statements(scope dotScope) ([]*Node, error) {
+
+    mentioned := make([]*Node, 0)
+
+    for p.pos < len(p.tokens) && p.peek().text != "}" {
+        if p.peek().text == ";" {
+            p.next()
+            continue
+        }
+
+        switch {
+        case p.keyword("graph") || p.keyword("node") || p.keyword("edge"):
+            kind := strings.ToLower(p.next().text)
+            attrs, err := p.attributes()
+            if err != nil {
+                return nil, err
+            }
+            for k, v := range attrs {
+                switch kind {
+                case "node":
+                    scope.node[k] = v
+                case "edge":
+                    scope.edge[k] = v
+                }
+            }
+            continue
+
+        case p.peek().id && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "=":
+            // graph attribute
+            p.pos += 3
+            continue
+        }
+
+        nodes, err := p.operand(scope)
+        if err != nil {
+            return nil, err
+        }
+        mentioned = append(mentioned, nodes...)
+
+        if op := p.peek().text; op != "->" && op != "--" {
+            attrs, err := p.attributes()
+            if err != nil {
+                return nil, err
+            }
+            for _, n := range nodes {
+                p.setNodeAttributes(n, attrs)
+            }
+            continue
+        }
+
+        // an edge chain, every step links all nodes on both sides
+        chain := [][]*Node{nodes}
+        for op := p.peek().text; op == "->" || op == "--"; op = p.peek().text {
+            p.next()
+            nodes, err := p.operand(scope)
+            if err != nil {
+                return nil, err
+            }
+            mentioned = append(mentioned, nodes...)
+            chain = append(chain, nodes)
+        }
+
+        attrs, err := p.attributes()
+        if err != nil {
+            return nil, err
+        }
+        all := make(map[string]string)
+        for k, v := range scope.edge {
+            all[k] = v
+        }
+        for k, v := range attrs {
+            all[k] = v
+        }
+
+        for i := 1; i < len(chain); i++ {
+            for _, from := range chain[i-1] {
+                for _, to := range chain[i] {
+                    if err := p.link(from, to, all); err != nil {
+                        return nil, err
+                    }
+                }
+            }
+        }
+    }
+
+    return mentioned, nil
+}
+
+// operand parses a node id or a subgraph and returns its nodes.
+func (p *dotParser) operand(scope dotScope) ([]*Node, error) {
+
+    if p.keyword("subgraph") || p.peek().text == "{" {
+        if p.keyword("subgraph") {
+            p.next()
+            if p.peek().id {
+                p.next()
+            }
+        }
+        if err := p.expect("{"); err != nil {
+            return nil, err
+        }
+        nodes, err := p.statements(scope.child())
+        if err != nil {
+            return nil, err
+        }
+        return nodes, p.expect("}")
+    }
+
+    t := p.next()
+    if !t.id {
+        return nil, errors.New("Expected node id but found " + strconv.Quote(t.text))
+    }
+
+    // skip a port
+    for p.peek().text == ":" {
+        p.next()
+        p.next()
+    }
+
+    n, ok := p.nodes[t.text]
+    if !ok {
+        n = NewNode()
+        n.Properties["id"] = t.text
+        p.setNodeAttributes(n, scope.node)
+        p.nodes[t.text] = n
+        p.g.insertNode(n)
+    }
+
+    return []*Node{n}, nil
+}
+
+// attributes parses any number of bracketed attribute lists.
+func (p *dotParser) attributes() (map[string]string, error) {
+
+    attrs := make(map[string]string)
+
+    for p.peek().text == "[" {
+        p.next()
+        for p.peek().text != "]" {
+            key := p.next()
+            if !key.id {
+                return nil, errors.New("Expected attribute name but found " + strconv.Quote(key.text))
+            }
+            if err := p.expect("="); err != nil {
+                return nil, err
+            }
+            value := p.next()
+            if !value.id {
+                return nil, errors.New("Expected attribute value but found " + strconv.Quote(value.text))
+            }
+            attrs[key.text] = value.text
+            if t := p.peek().text; t == "," || t == ";" {
+                p.next()
+            }
+        }
+        p.next()
+    }
+
+    return attrs, nil
+}
+
+// setNodeAttributes sets "name" before the other attributes, so that a
+// "label" beside it is kept as "label" whatever the map order.
+func (p *dotParser) setNodeAttributes(n *Node, attrs map[string]string) {
+
+    if name, ok := attrs["name"]; ok {
+        n.AddProperty("name", name)
+    }
+    for k, v := range attrs {
+        switch {
+        case k == "name":
+        case k == "label" && !n.HasProperty("name"):
+            n.AddProperty("name", v)
+        default:
+            n.AddProperty(k, v)
+        }
+    }
+}
+
+func (p *dotParser) link(from *Node, to *Node, attrs map[string]string) error {
+
+    e := NewEdge()
+    for k, v := range attrs {
+        e.Properties[k] = v
+    }
+
+    if !p.directed || attrs["dir"] == "none" {
+        e.Properties["directed"] = "false"
+        delete(e.Properties, "dir")
+    }
+
+    label := attrs["label"]
+    if value, ok := attrs["distance"]; ok {
+        v, err := strconv.ParseFloat(value, 64)
+        if err != nil {
+            return err
+        }
+        e.SetDistance(v)
+        delete(e.Properties, "distance")
+
+        // undo the distance WriteDOT adds to labels
+        label = strings.TrimSpace(strings.TrimSuffix(label, "("+value+")"))
+        e.Properties["label"] = label
+    } else if value, ok := attrs["weight"]; ok {
+        v, err := strconv.ParseFloat(value, 64)
+        if err != nil {
+            return err
+        }
+        e.SetWeight(v)
+    }
+
+    if _, named := e.Properties["name"]; !named {
+        delete(e.Properties, "label")
+        if len(label) > 0 {
+            e.Properties["name"] = label
+        }
+    }
+    e.kind = e.Properties["name"]
+
+    return e.Link(from, to)
+}
@@ -0,0 +1,126 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+
+    g := NewGraph("g")
+    a, _ := g.AddNode("a", "A")
+    b, _ := g.AddNode("b", `say "hi"`)
+    c, _ := g.AddNode("c", "C")
+    g.AddEdge("ab", "knows", 0.5, a, b)
+    g.AddEdge("bc", "likes", 1, b, c)
+    for _, e := range g.edges() {
+        if e.GetProperty("id") == "bc" {
+            e.AddProperty("directed", "false")
+        }
+    }
+
+    var buf bytes.Buffer
+    path := Path{Path: []Edge{{ParentNode: a, ChildNode: b}}}
+    if err := g.WriteDOT(&buf, DOTOptions{Highlight: &path}); err != nil {
+        t.Fatal(err)
+    }
+    want := `digraph "g" {
+    "a" ["color"="red", "label"="A"];
+    "b" ["color"="red", "label"="say \"hi\""];
+    "c" ["label"="C"];
+    "a" -> "b" ["color"="red", "distance"="2", "label"="knows (2)", "penwidth"="2"];
+    "b" -> "c" ["dir"="none", "distance"="1", "label"="likes (1)"];
+}
+`
+    if got := buf.String(); got != want {
+        t.Errorf("DOT\n%s\nwant\n%s", got, want)
+    }
+
+    buf.Reset()
+    opts := DOTOptions{HideDistance: true, NodeAttributes: map[string]string{"id": "tooltip"}}
+    if err := g.WriteDOT(&buf, opts); err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        `"a" ["label"="A", "tooltip"="a"];`,
+        `"a" -> "b" ["distance"="2", "label"="knows"];`,
+        `"b" -> "c" ["dir"="none", "distance"="1", "label"="likes"];`,
+    } {
+        if !strings.Contains(buf.String(), want) {
+            t.Errorf("DOT lacks %s in\n%s", want, buf.String())
+        }
+    }
+}
+
+func TestDOTRoundTrip(t *testing.T) {
+
+    g := exchangeGraph(t)
+    var buf bytes.Buffer
+    if err := g.WriteDOT(&buf, DOTOptions{NodeAttributes: map[string]string{"city": "city"}}); err != nil {
+        t.Fatal(err)
+    }
+    h, err := ReadDOT(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if h.id != "sample" {
+        t.Errorf("graph id %q, want sample", h.id)
+    }
+    sameGraph(t, "DOT", g, h)
+}
+
+func TestReadDOT(t *testing.T) {
+
+    src := `/* defaults apply to later statements
+   and nested subgraphs */
+graph G {
+# a line comment
+    node [color=red];
+    a [label="Alpha"]; // a trailing comment
+    a -- b -- c [weight=4];
+    subgraph s { edge [distance=3]; c -- d }
+    "e" + "f";
+}`
+
+    g, err := ReadDOT(strings.NewReader(src))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if g.id != "G" || len(g.nodes) != 5 {
+        t.Fatalf("graph %q with %d nodes, want G with 5", g.id, len(g.nodes))
+    }
+    a, _ := g.GetNodeById("a")
+    d, _ := g.GetNodeById("d")
+    if _, ok := g.GetNodeById("ef"); !ok {
+        t.Errorf("concatenated id ef is missing")
+    }
+    if a.GetProperty("name") != "Alpha" || a.GetProperty("color") != "red" || d.GetProperty("color") != "red" {
+        t.Errorf("node properties %v and %v", a.copyProperties(), d.copyProperties())
+    }
+    if got, want := edgeList(g), "a b 0.25 \nb c 0.25 \nc d 3 "; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        if e.GetProperty("directed") != "false" {
+            t.Errorf("edge %s %s of an undirected graph is directed", e.ParentNode.GetProperty("id"), e.ChildNode.GetProperty("id"))
+        }
+    }
+
+    for _, bad := range []string{
+        `graph {`,
+        `tree { a }`,
+        `digraph { a -> b [distance=far] }`,
+        `digraph { "a }`,
+        `digraph { /* a }`,
+    } {
+        if _, err := ReadDOT(strings.NewReader(bad)); err == nil {
+            t.Errorf("reading %s did not fail", bad)
+        }
+    }
+}
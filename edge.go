@@ -17,6 +17,8 @@ type Edge struct {
     ChildNode   *Node
     Distance    float64
     Properties  map[string]string
+    values      map[string]typedValue
+    kind        string
     lock        sync.RWMutex
 }
 
@@ -38,6 +40,7 @@ func (e *Edge) AddProperty(key string, value string) {
     defer e.lock.Unlock()
     
     e.Properties[key] = value
+    delete(e.values, key)
 }
 
 // RemProperty removes a property from the given edge.
@@ -50,6 +53,7 @@ func (e *Edge) RemProperty(key string) {
     defer e.lock.Unlock()
     
     delete(e.Properties, key)
+    delete(e.values, key)
 }
 
 // GetProperty returns a property from the given edge.
@@ -80,14 +84,35 @@ func (e *Edge) HasProperty(key string) bool {
     return false
 }
 
+// copyProperties returns a copy of the edge properties.
+func (e *Edge) copyProperties() map[string]string {
+    
+    e.lock.RLock()
+    defer e.lock.RUnlock()
+    
+    out := make(map[string]string, len(e.Properties))
+    for key, value := range e.Properties {
+        out[key] = value
+    }
+    
+    return out
+}
+
 // Link connects an edge to both an input and output
-// node.
+// node. It fails if the schema of the input node's graph
+// does not allow the edge.
 func (e *Edge) Link(input *Node, output *Node) error {
     
     if e == nil || input == nil || output == nil {
         return errors.New("Missing Edge and/or Nodes")
     }
     
+    if s := input.schema(); s != nil {
+        if err := s.allowEdge(e, e.Type(), input, output); err != nil {
+            return err
+        }
+    }
+    
     e.linkTo(input, true)
     e.linkTo(output, false)
     
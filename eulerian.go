@@ -0,0 +1,423 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+)
+
+// maxPostmanOddNodes limits the number of odd degree nodes the
+// undirected Chinese postman solver pairs up exactly.
+const maxPostmanOddNodes = 20
+
+// Trail is a walk through the graph. Nodes holds the visited nodes in
+// order, one more than Edges, and Distance the sum of the edge distances.
+// An edge is listed as often as it is traversed.
+type Trail struct {
+    Nodes     []*Node
+    Edges     []*Edge
+    Distance  float64
+}
+
+// HasEulerianPath returns true if a walk exists that uses every edge
+// exactly once. When directed is false edges can be walked both ways.
+func (g *Graph) HasEulerianPath(directed bool) bool {
+    _, err := g.EulerianPath(directed)
+    return err == nil
+}
+
+// HasEulerianCircuit returns true if a closed walk exists that uses
+// every edge exactly once. When directed is false edges can be walked
+// both ways.
+func (g *Graph) HasEulerianCircuit(directed bool) bool {
+    _, err := g.EulerianCircuit(directed)
+    return err == nil
+}
+
+// EulerianPath returns a walk that uses every edge exactly once, using
+// Hierholzer's algorithm. When directed is false edges can be walked
+// both ways. A circuit is returned if one exists.
+func (g *Graph) EulerianPath(directed bool) (Trail, error) {
+
+    if g == nil {
+        return Trail{}, errors.New("Graph is empty or nil")
+    }
+
+    return g.eulerian(g.edges(), directed, false)
+}
+
+// EulerianCircuit returns a closed walk that uses every edge exactly
+// once, using Hierholzer's algorithm. When directed is false edges can
+// be walked both ways.
+func (g *Graph) EulerianCircuit(directed bool) (Trail, error) {
+
+    if g == nil {
+        return Trail{}, errors.New("Graph is empty or nil")
+    }
+
+    return g.eulerian(g.edges(), directed, true)
+}
+
+// eulerian runs Hierholzer's algorithm over a list of edges, which may
+// hold the same edge more than once.
+func (g *Graph) eulerian(edges []*Edge, directed bool, circuit bool) (Trail, error) {
+
+    idx := g.nodeIndexes()
+    n := len(g.nodes)
+
+    if len(edges) == 0 {
+        return Trail{Nodes: []*Node{}, Edges: []*Edge{}}, nil
+    }
+
+    // balance is out minus in for directed graphs and the degree
+    // otherwise
+    balance := make([]int, n)
+    adj := make([][]int, n)
+    ends := make([][2]int, len(edges))
+
+    for k, edge := range edges {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        ends[k] = [2]int{u, v}
+        adj[u] = append(adj[u], k)
+        if directed {
+            balance[u]++
+            balance[v]--
+        } else {
+            balance[u]++
+            balance[v]++
+            if u != v {
+                adj[v] = append(adj[v], k)
+            }
+        }
+    }
+
+    start := ends[0][0]
+    odd := 0
+    for v, b := range balance {
+        if directed {
+            switch {
+            case b == 0:
+            case b == 1:
+                start = v
+                odd++
+            case b == -1:
+                odd++
+            default:
+                return Trail{}, errors.New("Graph has no Eulerian path")
+            }
+        } else if b%2 != 0 {
+            start = v
+            odd++
+        }
+    }
+
+    if odd > 0 && circuit {
+        return Trail{}, errors.New("Graph has no Eulerian circuit")
+    }
+    if odd > 2 {
+        return Trail{}, errors.New("Graph has no Eulerian path")
+    }
+
+    order, walked := hierholzer(adj, ends, start)
+    if len(walked) != len(edges) {
+        return Trail{}, errors.New("Edges are not connected")
+    }
+
+    t := Trail{Nodes: make([]*Node, len(order)), Edges: make([]*Edge, len(walked))}
+    for i, v := range order {
+        t.Nodes[i] = g.nodes[v]
+    }
+    for i, k := range walked {
+        t.Edges[i] = edges[k]
+        t.Distance += edges[k].Distance
+    }
+
+    return t, nil
+}
+
+// hierholzer walks unused edges from start until it gets stuck, splicing
+// in detours until every reachable edge has been used. adj lists the
+// edges that can be followed from every node and ends the two nodes of
+// every edge. It returns the visited nodes and the edges followed.
+func hierholzer(adj [][]int, ends [][2]int, start int) ([]int, []int) {
+
+    // each stack entry holds a node and the edge used to reach it
+    used := make([]bool, len(ends))
+    next := make([]int, len(adj))
+    stack := [][2]int{{start, -1}}
+    nodes := make([]int, 0, len(ends)+1)
+    walked := make([]int, 0, len(ends))
+
+    for len(stack) > 0 {
+        u := stack[len(stack)-1][0]
+
+        for next[u] < len(adj[u]) && used[adj[u][next[u]]] {
+            next[u]++
+        }
+
+        if next[u] < len(adj[u]) {
+            k := adj[u][next[u]]
+            used[k] = true
+            v := ends[k][1]
+            if v == u {
+                v = ends[k][0]
+            }
+            stack = append(stack, [2]int{v, k})
+            continue
+        }
+
+        top := stack[len(stack)-1]
+        stack = stack[:len(stack)-1]
+        nodes = append(nodes, top[0])
+        if top[1] >= 0 {
+            walked = append(walked, top[1])
+        }
+    }
+
+    for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+        nodes[i], nodes[j] = nodes[j], nodes[i]
+    }
+    for i, j := 0, len(walked)-1; i < j; i, j = i+1, j-1 {
+        walked[i], walked[j] = walked[j], walked[i]
+    }
+
+    return nodes, walked
+}
+
+// ChinesePostman returns the shortest closed walk that traverses every
+// edge at least once, using Edge.Distance as cost. When directed is false
+// edges can be walked both ways; the odd degree nodes are then paired
+// exactly, which is supported for up to 20 such nodes.
+func (g *Graph) ChinesePostman(directed bool) (Trail, error) {
+
+    if g == nil {
+        return Trail{}, errors.New("Graph is empty or nil")
+    }
+
+    var extra []*Edge
+    var err error
+    if directed {
+        extra, err = g.postmanDirected()
+    } else {
+        extra, err = g.postmanUndirected()
+    }
+    if err != nil {
+        return Trail{}, err
+    }
+
+    return g.eulerian(append(g.edges(), extra...), directed, true)
+}
+
+// postmanUndirected returns the edges to walk twice so that every node
+// has an even degree, by pairing the odd degree nodes along shortest
+// paths at the lowest total distance.
+func (g *Graph) postmanUndirected() ([]*Edge, error) {
+
+    idx := g.nodeIndexes()
+    degree := make([]int, len(g.nodes))
+    for _, edge := range g.edges() {
+        degree[idx[edge.ParentNode]]++
+        degree[idx[edge.ChildNode]]++
+    }
+
+    odd := make([]int, 0)
+    for v, d := range degree {
+        if d%2 != 0 {
+            odd = append(odd, v)
+        }
+    }
+    if len(odd) == 0 {
+        return []*Edge{}, nil
+    }
+    if len(odd) > maxPostmanOddNodes {
+        return nil, errors.New("Too many odd degree nodes")
+    }
+
+    adj := g.arcs(false)
+    dist := make([][]float64, len(odd))
+    via := make([][]incidence, len(odd))
+    for i, v := range odd {
+        d, p := shortestPaths(adj, v)
+        dist[i] = make([]float64, len(odd))
+        for j, w := range odd {
+            dist[i][j] = d[w]
+        }
+        via[i] = p
+    }
+
+    pairs, ok := minPairing(dist)
+    if !ok {
+        return nil, errors.New("Edges are not connected")
+    }
+
+    extra := make([]*Edge, 0)
+    for _, p := range pairs {
+        for _, arc := range walkTo(via[p[0]], odd[p[1]]) {
+            extra = append(extra, arc.edge)
+        }
+    }
+
+    return extra, nil
+}
+
+// minPairing splits an even number of points into pairs at the lowest
+// total distance, by dynamic programming over the subsets of points. It
+// returns false if some points can not be paired at a finite distance.
+func minPairing(dist [][]float64) ([][2]int, bool) {
+
+    n := len(dist)
+
+    // best[mask] is the lowest cost of pairing the points in mask,
+    // always pairing the lowest unpaired point first
+    full := 1<<uint(n) - 1
+    best := make([]float64, full+1)
+    pair := make([][2]int, full+1)
+    for mask := 1; mask <= full; mask++ {
+        best[mask] = math.Inf(1)
+    }
+
+    for mask := 0; mask < full; mask++ {
+        if math.IsInf(best[mask], 1) {
+            continue
+        }
+        i := 0
+        for mask&(1<<uint(i)) != 0 {
+            i++
+        }
+        for j := i + 1; j < n; j++ {
+            if mask&(1<<uint(j)) != 0 {
+                continue
+            }
+            next := mask | 1<<uint(i) | 1<<uint(j)
+            if c := best[mask] + dist[i][j]; c < best[next] {
+                best[next] = c
+                pair[next] = [2]int{i, j}
+            }
+        }
+    }
+
+    if math.IsInf(best[full], 1) {
+        return nil, false
+    }
+
+    out := make([][2]int, 0, n/2)
+    for mask := full; mask != 0; {
+        p := pair[mask]
+        out = append(out, p)
+        mask &^= 1<<uint(p[0]) | 1<<uint(p[1])
+    }
+
+    return out, true
+}
+
+// postmanDirected returns the edges to walk again so that every node is
+// left as often as it is entered, as a minimum cost flow from the nodes
+// with more incoming edges to the nodes with more outgoing edges.
+func (g *Graph) postmanDirected() ([]*Edge, error) {
+
+    type arc struct {
+        to, rev  int
+        cap      int
+        cost     float64
+        edge     int
+    }
+
+    idx := g.nodeIndexes()
+    edges := g.edges()
+    n := len(g.nodes)
+    source, sink := n, n+1
+    net := make([][]arc, n+2)
+
+    addArc := func(u, v, capacity int, cost float64, edge int) {
+        net[u] = append(net[u], arc{v, len(net[v]), capacity, cost, edge})
+        net[v] = append(net[v], arc{u, len(net[u]) - 1, 0, -cost, -1})
+    }
+
+    balance := make([]int, n)
+    for k, edge := range edges {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        balance[u]--
+        balance[v]++
+        addArc(u, v, len(edges)*n+1, edge.Distance, k)
+    }
+
+    required := 0
+    for v, b := range balance {
+        if b > 0 {
+            addArc(source, v, b, 0, -1)
+            required += b
+        } else if b < 0 {
+            addArc(v, sink, -b, 0, -1)
+        }
+    }
+
+    // successive shortest paths, Bellman-Ford handles the negative
+    // costs of the residual arcs
+    for flow := 0; flow < required; {
+        dist := make([]float64, n+2)
+        prevNode := make([]int, n+2)
+        prevArc := make([]int, n+2)
+        for i := range dist {
+            dist[i] = math.Inf(1)
+        }
+        dist[source] = 0
+
+        for round := 0; round < n+2; round++ {
+            changed := false
+            for u := range net {
+                if math.IsInf(dist[u], 1) {
+                    continue
+                }
+                for i, a := range net[u] {
+                    if a.cap > 0 && dist[u]+a.cost < dist[a.to] {
+                        dist[a.to] = dist[u] + a.cost
+                        prevNode[a.to] = u
+                        prevArc[a.to] = i
+                        changed = true
+                    }
+                }
+            }
+            if !changed {
+                break
+            }
+        }
+
+        if math.IsInf(dist[sink], 1) {
+            return nil, errors.New("Edges are not strongly connected")
+        }
+
+        push := required - flow
+        for v := sink; v != source; v = prevNode[v] {
+            if c := net[prevNode[v]][prevArc[v]].cap; c < push {
+                push = c
+            }
+        }
+        for v := sink; v != source; v = prevNode[v] {
+            a := &net[prevNode[v]][prevArc[v]]
+            a.cap -= push
+            net[v][a.rev].cap += push
+        }
+        flow += push
+    }
+
+    extra := make([]*Edge, 0)
+    for u := 0; u < n; u++ {
+        for _, a := range net[u] {
+            if a.edge < 0 {
+                continue
+            }
+            // flow on an arc shows up as capacity on its reverse
+            for f := net[a.to][a.rev].cap; f > 0; f-- {
+                extra = append(extra, edges[a.edge])
+            }
+        }
+    }
+
+    return extra, nil
+}
@@ -0,0 +1,101 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "testing"
+)
+
+// checkTrail reports whether the trail is a walk along its edges,
+// following their direction if directed is true.
+func checkTrail(t *testing.T, name string, trail Trail, directed bool) {
+
+    if len(trail.Nodes) != len(trail.Edges)+1 {
+        t.Errorf("%s: %d nodes for %d edges", name, len(trail.Nodes), len(trail.Edges))
+        return
+    }
+    for i, e := range trail.Edges {
+        from, to := trail.Nodes[i], trail.Nodes[i+1]
+        forward := e.ParentNode == from && e.ChildNode == to
+        backward := e.ParentNode == to && e.ChildNode == from
+        if !forward && (directed || !backward) {
+            t.Errorf("%s: edge %s does not lead from %s to %s", name, e.GetProperty("id"), from.GetProperty("id"), to.GetProperty("id"))
+        }
+    }
+}
+
+func TestEulerian(t *testing.T) {
+
+    // the seven bridges of Königsberg join four land masses of odd degree
+    konigsberg := edgeGraph(t, "A B", "A B", "A C", "A C", "A D", "B D", "C D")
+    if konigsberg.HasEulerianPath(false) || konigsberg.HasEulerianCircuit(false) {
+        t.Errorf("Königsberg has an Eulerian walk")
+    }
+
+    // the house of Nikolaus can be drawn from either bottom corner only
+    house := edgeGraph(t, "a b", "b c", "c d", "d a", "a c", "b d", "c e", "d e")
+    trail, err := house.EulerianPath(false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    checkTrail(t, "house", trail, false)
+    ends := trail.Nodes[0].GetProperty("id") + trail.Nodes[len(trail.Nodes)-1].GetProperty("id")
+    if len(trail.Edges) != 8 || trail.Distance != 8 || (ends != "ab" && ends != "ba") {
+        t.Errorf("house: %d edges from %s, want 8 between a and b", len(trail.Edges), ends)
+    }
+    if house.HasEulerianCircuit(false) {
+        t.Errorf("house has an Eulerian circuit")
+    }
+
+    cycle := edgeGraph(t, "a b", "b c", "c a", "a d", "d a")
+    trail, err = cycle.EulerianCircuit(true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    checkTrail(t, "cycle", trail, true)
+    if len(trail.Edges) != 5 || trail.Nodes[0] != trail.Nodes[5] {
+        t.Errorf("cycle: circuit of %d edges from %s to %s", len(trail.Edges), trail.Nodes[0].GetProperty("id"), trail.Nodes[5].GetProperty("id"))
+    }
+    if edgeGraph(t, "a b", "b c", "a c").HasEulerianPath(true) {
+        t.Errorf("a directed walk uses a b, b c and a c")
+    }
+}
+
+func TestChinesePostman(t *testing.T) {
+
+    tests := []struct {
+        name      string
+        g         *Graph
+        directed  bool
+        edges     int
+        distance  float64
+    }{
+        // a and c are odd, the cheapest way between them is a b c
+        {"square with diagonal", edgeGraph(t, "a b 1", "b c 1", "c d 1", "d a 1", "a c 3"), false, 7, 9},
+        // A B and C D are walked twice
+        {"Königsberg", edgeGraph(t, "A B", "A B", "A C", "A C", "A D", "B D", "C D"), false, 9, 9},
+        // c has one more edge in than out, so c a is walked twice
+        {"directed", edgeGraph(t, "a b 1", "b c 1", "c a 2", "a c 1"), true, 5, 7},
+        {"directed circuit", edgeGraph(t, "a b 2", "b a 3"), true, 2, 5},
+    }
+
+    for _, test := range tests {
+        trail, err := test.g.ChinesePostman(test.directed)
+        if err != nil {
+            t.Errorf("%s: %v", test.name, err)
+            continue
+        }
+        checkTrail(t, test.name, trail, test.directed)
+        if len(trail.Edges) != test.edges || trail.Distance != test.distance || trail.Nodes[0] != trail.Nodes[len(trail.Nodes)-1] {
+            t.Errorf("%s: closed %v walk of %d edges at %v, want %d at %v", test.name,
+                trail.Nodes[0] == trail.Nodes[len(trail.Nodes)-1], len(trail.Edges), trail.Distance, test.edges, test.distance)
+        }
+    }
+
+    if _, err := edgeGraph(t, "a b", "b c").ChinesePostman(true); err == nil {
+        t.Errorf("a directed postman walk back from c exists")
+    }
+}
@@ -6,7 +6,9 @@
 package graph
 
 import (
+    "container/heap"
     "errors"
+    "math"
 )
 
 const (
@@ -168,3 +170,94 @@ func (g *Graph) Search(root *Node) ([]Path, error) {
     
     return paths, nil
 }
+
+
+// arcs returns, by index, the edges that can be followed from every
+// node. When directed is false every edge can be followed both ways.
+func (g *Graph) arcs(directed bool) [][]incidence {
+
+    idx := g.nodeIndexes()
+    adj := make([][]incidence, len(g.nodes))
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        adj[u] = append(adj[u], incidence{v, edge})
+        if !directed && u != v {
+            adj[v] = append(adj[v], incidence{u, edge})
+        }
+    }
+
+    return adj
+}
+
+// distItem is a node index queued at a distance.
+type distItem struct {
+    node  int
+    dist  float64
+}
+
+// distQueue is a priority queue of nodes ordered by distance.
+type distQueue []distItem
+
+func (q distQueue) Len() int            { return len(q) }
+func (q distQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q distQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *distQueue) Push(x interface{}) { *q = append(*q, x.(distItem)) }
+func (q *distQueue) Pop() interface{} {
+    old := *q
+    last := old[len(old)-1]
+    *q = old[:len(old)-1]
+    return last
+}
+
+// shortestPaths runs Dijkstra from src over an arc list. It returns the
+// distance to every node, +Inf when unreachable, and the arc followed to
+// reach each node, with a nil edge for src and unreachable nodes.
+func shortestPaths(adj [][]incidence, src int) ([]float64, []incidence) {
+
+    dist := make([]float64, len(adj))
+    via := make([]incidence, len(adj))
+    for i := range dist {
+        dist[i] = math.Inf(1)
+    }
+    dist[src] = 0
+
+    done := make([]bool, len(adj))
+    q := &distQueue{}
+    heap.Push(q, distItem{src, 0})
+
+    for q.Len() > 0 {
+        u := heap.Pop(q).(distItem).node
+        if done[u] {
+            continue
+        }
+        done[u] = true
+
+        for _, arc := range adj[u] {
+            if d := dist[u] + arc.edge.Distance; d < dist[arc.to] {
+                dist[arc.to] = d
+                via[arc.to] = incidence{u, arc.edge}
+                heap.Push(q, distItem{arc.to, d})
+            }
+        }
+    }
+
+    return dist, via
+}
+
+// walkTo returns the arcs of the shortest path found by shortestPaths
+// from its source to dst, in order. Each arc holds the node it leaves.
+func walkTo(via []incidence, dst int) []incidence {
+
+    out := make([]incidence, 0)
+    for v := dst; via[v].edge != nil; v = via[v].to {
+        out = append(out, via[v])
+    }
+
+    for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+        out[i], out[j] = out[j], out[i]
+    }
+
+    return out
+}
@@ -0,0 +1,493 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+    "math/rand"
+    "strconv"
+)
+
+// WeightFunc returns the weight of the next generated edge.
+type WeightFunc func(r *rand.Rand) float64
+
+// ConstantWeight gives every edge the same weight.
+func ConstantWeight(w float64) WeightFunc {
+    return func(r *rand.Rand) float64 {
+        return w
+    }
+}
+
+// UniformWeight draws edge weights uniformly from [min, max).
+func UniformWeight(min float64, max float64) WeightFunc {
+    return func(r *rand.Rand) float64 {
+        return min + r.Float64()*(max-min)
+    }
+}
+
+// NormalWeight draws edge weights from a normal distribution.
+func NormalWeight(mean float64, stddev float64) WeightFunc {
+    return func(r *rand.Rand) float64 {
+        return mean + r.NormFloat64()*stddev
+    }
+}
+
+// ExponentialWeight draws edge weights from an exponential distribution
+// with the given mean.
+func ExponentialWeight(mean float64) WeightFunc {
+    return func(r *rand.Rand) float64 {
+        return r.ExpFloat64() * mean
+    }
+}
+
+// GeneratorOptions configures the graph generators. Nodes are given the
+// ids and names "0" to "n-1" and edges the id "<parent>-<child>" and the
+// name "link". Edges point from the older node to the newer one unless
+// stated otherwise.
+type GeneratorOptions struct {
+    // ID is the id of the generated graph.
+    ID        string
+    // Seed makes every run with the same options produce the same graph.
+    Seed      int64
+    // Weight draws the weight of every edge, all weights are 1 if nil.
+    Weight    WeightFunc
+    // Directed lets the Erdős–Rényi generators pick ordered pairs of
+    // nodes, so both directions between two nodes may be linked.
+    Directed  bool
+}
+
+// generator builds a graph of numbered nodes.
+type generator struct {
+    g      *Graph
+    r      *rand.Rand
+    opts   GeneratorOptions
+    nodes  []*Node
+}
+
+func newGenerator(n int, opts GeneratorOptions) (*generator, error) {
+
+    if n < 0 {
+        return nil, errors.New("Number of nodes must not be negative")
+    }
+
+    gen := &generator{
+        g:      NewGraph(opts.ID),
+        r:      rand.New(rand.NewSource(opts.Seed)),
+        opts:   opts,
+        nodes:  make([]*Node, n),
+    }
+
+    for i := range gen.nodes {
+        id := strconv.Itoa(i)
+        node, err := gen.g.AddNode(id, id)
+        if err != nil {
+            return nil, err
+        }
+        gen.nodes[i] = node
+    }
+
+    return gen, nil
+}
+
+func (gen *generator) link(u int, v int) error {
+
+    weight := 1.0
+    if gen.opts.Weight != nil {
+        weight = gen.opts.Weight(gen.r)
+    }
+
+    id := strconv.Itoa(u) + "-" + strconv.Itoa(v)
+    return gen.g.AddEdge(id, "link", weight, gen.nodes[u], gen.nodes[v])
+}
+
+// NewGNP returns an Erdős–Rényi random graph of n nodes where every pair
+// of nodes is linked with probability p.
+func NewGNP(n int, p float64, opts GeneratorOptions) (*Graph, error) {
+
+    if p < 0 || p > 1 || math.IsNaN(p) {
+        return nil, errors.New("Probability must be between 0 and 1")
+    }
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+    if p == 0 || n < 2 {
+        return gen.g, nil
+    }
+
+    // skip over the pairs that are not linked, drawing the length of
+    // each gap from a geometric distribution
+    pairs := n * (n - 1)
+    if !opts.Directed {
+        pairs /= 2
+    }
+    lp := math.Log(1 - p)
+
+    for k := -1; ; {
+        skip := 0
+        if p < 1 {
+            gap := math.Log(1-gen.r.Float64()) / lp
+            if gap >= float64(pairs-k-1) {
+                break
+            }
+            skip = int(gap)
+        }
+        k += 1 + skip
+        if k >= pairs {
+            break
+        }
+
+        u, v := pairAt(k, n, opts.Directed)
+        if err := gen.link(u, v); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// pairAt returns the k-th pair of n nodes. Undirected pairs are ordered
+// (0,1), (0,2), (1,2), (0,3) and so on, directed pairs by parent.
+func pairAt(k int, n int, directed bool) (int, int) {
+
+    if directed {
+        u := k / (n - 1)
+        v := k % (n - 1)
+        if v >= u {
+            v++
+        }
+        return u, v
+    }
+
+    v := int((1 + math.Sqrt(float64(1+8*k))) / 2)
+    for v*(v-1)/2 > k {
+        v--
+    }
+    for (v+1)*v/2 <= k {
+        v++
+    }
+    return k - v*(v-1)/2, v
+}
+
+// NewGNM returns an Erdős–Rényi random graph of n nodes with exactly m
+// edges between distinct pairs of nodes chosen uniformly.
+func NewGNM(n int, m int, opts GeneratorOptions) (*Graph, error) {
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    pairs := 0
+    if n > 1 {
+        pairs = n * (n - 1)
+        if !opts.Directed {
+            pairs /= 2
+        }
+    }
+    if m < 0 || m > pairs {
+        return nil, errors.New("Too many edges for the number of nodes")
+    }
+
+    // draw pair numbers without repeats, a partial Fisher-Yates shuffle
+    // over a sparse permutation
+    swapped := make(map[int]int)
+    for i := 0; i < m; i++ {
+        j := i + gen.r.Intn(pairs-i)
+        pick, ok := swapped[j]
+        if !ok {
+            pick = j
+        }
+        at, ok := swapped[i]
+        if !ok {
+            at = i
+        }
+        swapped[j] = at
+
+        u, v := pairAt(pick, n, opts.Directed)
+        if err := gen.link(u, v); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewBarabasiAlbert returns a scale-free random graph of n nodes grown
+// by preferential attachment: every node after the first m links to m
+// distinct earlier nodes chosen in proportion to their degree.
+func NewBarabasiAlbert(n int, m int, opts GeneratorOptions) (*Graph, error) {
+
+    if m < 1 || m >= n {
+        return nil, errors.New("Links per node must be at least 1 and less than the number of nodes")
+    }
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    // every node appears once per link it has, the first node links
+    // to all initial nodes
+    repeated := make([]int, 0, 2*n*m)
+    targets := make([]int, m)
+    for i := range targets {
+        targets[i] = i
+    }
+
+    for v := m; v < n; v++ {
+        for _, u := range targets {
+            if err := gen.link(u, v); err != nil {
+                return nil, err
+            }
+            repeated = append(repeated, u, v)
+        }
+
+        chosen := make(map[int]bool)
+        targets = targets[:0]
+        for len(targets) < m {
+            u := repeated[gen.r.Intn(len(repeated))]
+            if !chosen[u] {
+                chosen[u] = true
+                targets = append(targets, u)
+            }
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewWattsStrogatz returns a small world graph of n nodes: a ring where
+// every node links to its k nearest neighbours, k even, after which the
+// far end of each link is moved to a random node with probability beta.
+func NewWattsStrogatz(n int, k int, beta float64, opts GeneratorOptions) (*Graph, error) {
+
+    if k < 2 || k%2 != 0 || k >= n {
+        return nil, errors.New("Neighbours must be even, at least 2 and less than the number of nodes")
+    }
+    if beta < 0 || beta > 1 || math.IsNaN(beta) {
+        return nil, errors.New("Probability must be between 0 and 1")
+    }
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    linked := make(map[[2]int]bool)
+    key := func(u, v int) [2]int {
+        if u > v {
+            u, v = v, u
+        }
+        return [2]int{u, v}
+    }
+
+    ring := make([][2]int, 0, n*k/2)
+    for j := 1; j <= k/2; j++ {
+        for u := 0; u < n; u++ {
+            v := (u + j) % n
+            ring = append(ring, [2]int{u, v})
+            linked[key(u, v)] = true
+        }
+    }
+
+    for i, e := range ring {
+        u, v := e[0], e[1]
+        if gen.r.Float64() >= beta {
+            continue
+        }
+
+        // leave the link alone if u is already linked to every node
+        free := n - 1
+        for w := 0; w < n; w++ {
+            if w != u && linked[key(u, w)] {
+                free--
+            }
+        }
+        if free == 0 {
+            continue
+        }
+
+        w := gen.r.Intn(n)
+        for w == u || linked[key(u, w)] {
+            w = gen.r.Intn(n)
+        }
+        delete(linked, key(u, v))
+        linked[key(u, w)] = true
+        ring[i] = [2]int{u, w}
+    }
+
+    for _, e := range ring {
+        if err := gen.link(e[0], e[1]); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewGrid returns a two dimensional lattice of rows by cols nodes, each
+// linked to the node on its right and the node below it. Node i sits in
+// row i / cols and column i % cols, also stored as the "row" and "col"
+// properties.
+func NewGrid(rows int, cols int, opts GeneratorOptions) (*Graph, error) {
+
+    if rows < 0 || cols < 0 {
+        return nil, errors.New("Rows and columns must not be negative")
+    }
+
+    gen, err := newGenerator(rows*cols, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    for r := 0; r < rows; r++ {
+        for c := 0; c < cols; c++ {
+            u := r*cols + c
+            gen.nodes[u].AddProperty("row", strconv.Itoa(r))
+            gen.nodes[u].AddProperty("col", strconv.Itoa(c))
+            if c+1 < cols {
+                if err := gen.link(u, u+1); err != nil {
+                    return nil, err
+                }
+            }
+            if r+1 < rows {
+                if err := gen.link(u, u+cols); err != nil {
+                    return nil, err
+                }
+            }
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewComplete returns a graph of n nodes with every pair linked once.
+func NewComplete(n int, opts GeneratorOptions) (*Graph, error) {
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    for v := 1; v < n; v++ {
+        for u := 0; u < v; u++ {
+            if err := gen.link(u, v); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewStar returns a graph of n nodes with node 0 linked to all others.
+func NewStar(n int, opts GeneratorOptions) (*Graph, error) {
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    for v := 1; v < n; v++ {
+        if err := gen.link(0, v); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewPathGraph returns a graph of n nodes linked in a line.
+func NewPathGraph(n int, opts GeneratorOptions) (*Graph, error) {
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    for v := 1; v < n; v++ {
+        if err := gen.link(v-1, v); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewCycle returns a graph of n nodes linked in a ring, the last node
+// linking back to the first.
+func NewCycle(n int, opts GeneratorOptions) (*Graph, error) {
+
+    if n < 3 {
+        return nil, errors.New("A cycle requires at least 3 nodes")
+    }
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    for v := 1; v <= n; v++ {
+        if err := gen.link(v-1, v%n); err != nil {
+            return nil, err
+        }
+    }
+
+    return gen.g, nil
+}
+
+// NewRandomTree returns a tree of n nodes drawn uniformly from all
+// labelled trees, by decoding a random Prüfer sequence.
+func NewRandomTree(n int, opts GeneratorOptions) (*Graph, error) {
+
+    gen, err := newGenerator(n, opts)
+    if err != nil {
+        return nil, err
+    }
+    if n < 2 {
+        return gen.g, nil
+    }
+
+    seq := make([]int, n-2)
+    degree := make([]int, n)
+    for i := range degree {
+        degree[i] = 1
+    }
+    for i := range seq {
+        seq[i] = gen.r.Intn(n)
+        degree[seq[i]]++
+    }
+
+    // link every sequence entry to the smallest remaining leaf
+    ptr := 0
+    for degree[ptr] != 1 {
+        ptr++
+    }
+    leaf := ptr
+    for _, v := range seq {
+        if err := gen.link(v, leaf); err != nil {
+            return nil, err
+        }
+        degree[v]--
+        if degree[v] == 1 && v < ptr {
+            leaf = v
+            continue
+        }
+        ptr++
+        for degree[ptr] != 1 {
+            ptr++
+        }
+        leaf = ptr
+    }
+    if err := gen.link(n-1, leaf); err != nil {
+        return nil, err
+    }
+
+    return gen.g, nil
+}
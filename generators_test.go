@@ -0,0 +1,112 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "testing"
+)
+
+func TestGenerators(t *testing.T) {
+
+    opts := GeneratorOptions{Seed: 7}
+    directed := GeneratorOptions{Seed: 7, Directed: true}
+    build := func(g *Graph, err error) *Graph {
+        if err != nil {
+            t.Fatal(err)
+        }
+        return g
+    }
+
+    for _, test := range []struct {
+        name   string
+        g      *Graph
+        nodes  int
+        edges  int
+    }{
+        {"GNP none", build(NewGNP(5, 0, opts)), 5, 0},
+        {"GNP all", build(NewGNP(5, 1, opts)), 5, 10},
+        {"GNP all directed", build(NewGNP(5, 1, directed)), 5, 20},
+        {"GNM", build(NewGNM(10, 20, opts)), 10, 20},
+        {"GNM directed", build(NewGNM(4, 12, directed)), 4, 12},
+        {"BarabasiAlbert", build(NewBarabasiAlbert(20, 3, opts)), 20, 51},
+        {"WattsStrogatz", build(NewWattsStrogatz(20, 4, 0.3, opts)), 20, 40},
+        {"Grid", build(NewGrid(3, 4, opts)), 12, 17},
+        {"Complete", build(NewComplete(5, opts)), 5, 10},
+        {"Star", build(NewStar(5, opts)), 5, 4},
+        {"PathGraph", build(NewPathGraph(5, opts)), 5, 4},
+        {"Cycle", build(NewCycle(5, opts)), 5, 5},
+        {"RandomTree", build(NewRandomTree(10, opts)), 10, 9},
+    } {
+        edges := test.g.edges()
+        if len(test.g.nodes) != test.nodes || len(edges) != test.edges {
+            t.Errorf("%s: %d nodes and %d edges, want %d and %d", test.name, len(test.g.nodes), len(edges), test.nodes, test.edges)
+        }
+        // edge ids name the ends, so a repeated id is a repeated link
+        seen := make(map[string]bool)
+        for _, e := range edges {
+            id := e.GetProperty("id")
+            if seen[id] || e.ParentNode == e.ChildNode {
+                t.Errorf("%s: edge %s is repeated or a self loop", test.name, id)
+            }
+            seen[id] = true
+        }
+    }
+
+    // without rewiring every ring node keeps k neighbours
+    ring := build(NewWattsStrogatz(10, 4, 0, opts))
+    for _, n := range ring.nodes {
+        if in, out := n.NumLinks(); in+out != 4 {
+            t.Errorf("ring node %s has %d links, want 4", n.GetProperty("id"), in+out)
+        }
+    }
+
+    tree := build(NewRandomTree(10, opts))
+    if _, ok := tree.IsBipartite(); !ok || len(tree.Bridges()) != 9 {
+        t.Errorf("random tree is not a connected forest of bridges")
+    }
+
+    // the same seed gives the same graph, another seed another one
+    a := build(NewGNP(30, 0.2, opts))
+    b := build(NewGNP(30, 0.2, opts))
+    c := build(NewGNP(30, 0.2, GeneratorOptions{Seed: 8}))
+    if edgeIDs(a.edges()) != edgeIDs(b.edges()) {
+        t.Errorf("GNP differs between runs with the same seed")
+    }
+    if edgeIDs(a.edges()) == edgeIDs(c.edges()) {
+        t.Errorf("GNP is the same for different seeds")
+    }
+
+    weighted := build(NewPathGraph(3, GeneratorOptions{Weight: ConstantWeight(4)}))
+    for _, e := range weighted.edges() {
+        if e.Distance != 0.25 {
+            t.Errorf("edge %s has distance %v, want 0.25", e.GetProperty("id"), e.Distance)
+        }
+    }
+    uniform := build(NewComplete(10, GeneratorOptions{Weight: UniformWeight(2, 4)}))
+    for _, e := range uniform.edges() {
+        if e.Distance < 0.25 || e.Distance > 0.5 {
+            t.Errorf("edge %s has distance %v, want between 0.25 and 0.5", e.GetProperty("id"), e.Distance)
+        }
+    }
+
+    for name, err := range map[string]error{
+        "negative nodes":  second(NewGNP(-1, 0.5, opts)),
+        "probability":     second(NewGNP(5, 1.5, opts)),
+        "too many edges":  second(NewGNM(4, 7, opts)),
+        "too many links":  second(NewBarabasiAlbert(3, 3, opts)),
+        "odd neighbours":  second(NewWattsStrogatz(10, 3, 0.1, opts)),
+        "short cycle":     second(NewCycle(2, opts)),
+    } {
+        if err == nil {
+            t.Errorf("%s did not fail", name)
+        }
+    }
+}
+
+// second drops the graph returned by a generator.
+func second(g *Graph, err error) error {
+    return err
+}
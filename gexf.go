@@ -0,0 +1,405 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "encoding/xml"
+    "errors"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const gexfNamespace = "http://gexf.net/1.3"
+
+type gexfDoc struct {
+    XMLName  xml.Name   `xml:"gexf"`
+    Xmlns    string     `xml:"xmlns,attr,omitempty"`
+    Version  string     `xml:"version,attr,omitempty"`
+    Graph    gexfGraph  `xml:"graph"`
+}
+
+type gexfGraph struct {
+    ID               string            `xml:"id,attr,omitempty"`
+    DefaultEdgeType  string            `xml:"defaultedgetype,attr,omitempty"`
+    Mode             string            `xml:"mode,attr,omitempty"`
+    Attributes       []gexfAttributes  `xml:"attributes"`
+    Nodes            []gexfNode        `xml:"nodes>node"`
+    Edges            []gexfEdge        `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+    Class       string           `xml:"class,attr"`
+    Mode        string           `xml:"mode,attr,omitempty"`
+    Attributes  []gexfAttribute  `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+    ID       string   `xml:"id,attr"`
+    Title    string   `xml:"title,attr"`
+    Type     string   `xml:"type,attr"`
+    Default  *string  `xml:"default"`
+}
+
+type gexfNode struct {
+    ID         string       `xml:"id,attr"`
+    Label      string       `xml:"label,attr,omitempty"`
+    Start      string       `xml:"start,attr,omitempty"`
+    End        string       `xml:"end,attr,omitempty"`
+    StartOpen  string       `xml:"startopen,attr,omitempty"`
+    EndOpen    string       `xml:"endopen,attr,omitempty"`
+    Values     []gexfValue  `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+    ID         string       `xml:"id,attr,omitempty"`
+    Source     string       `xml:"source,attr"`
+    Target     string       `xml:"target,attr"`
+    Type       string       `xml:"type,attr,omitempty"`
+    Label      string       `xml:"label,attr,omitempty"`
+    Weight     string       `xml:"weight,attr,omitempty"`
+    Start      string       `xml:"start,attr,omitempty"`
+    End        string       `xml:"end,attr,omitempty"`
+    StartOpen  string       `xml:"startopen,attr,omitempty"`
+    EndOpen    string       `xml:"endopen,attr,omitempty"`
+    Values     []gexfValue  `xml:"attvalues>attvalue"`
+}
+
+type gexfValue struct {
+    For        string  `xml:"for,attr"`
+    Value      string  `xml:"value,attr"`
+    Start      string  `xml:"start,attr,omitempty"`
+    End        string  `xml:"end,attr,omitempty"`
+    StartOpen  string  `xml:"startopen,attr,omitempty"`
+    EndOpen    string  `xml:"endopen,attr,omitempty"`
+}
+
+// timedKey names the property holding the value of a dynamic attribute
+// over an interval, for example "population@[2000,2010]". Either end
+// may be empty.
+func timedKey(title string, start string, end string) string {
+    return title + "@[" + start + "," + end + "]"
+}
+
+// splitTimedKey reverses timedKey.
+func splitTimedKey(key string) (title string, start string, end string, ok bool) {
+
+    at := strings.LastIndex(key, "@[")
+    if at < 0 || !strings.HasSuffix(key, "]") {
+        return key, "", "", false
+    }
+    bounds := strings.Split(key[at+2:len(key)-1], ",")
+    if len(bounds) != 2 {
+        return key, "", "", false
+    }
+
+    return key[:at], bounds[0], bounds[1], true
+}
+
+// gexfAttributesOf declares an attribute for every property title found
+// in props and returns their ids by title.
+func gexfAttributesOf(props []map[string]string, class string) (gexfAttributes, map[string]string) {
+
+    values := make(map[string][]string)
+    dynamic := false
+    for _, p := range props {
+        for key, value := range p {
+            title, _, _, timed := splitTimedKey(key)
+            dynamic = dynamic || timed
+            values[title] = append(values[title], value)
+        }
+    }
+
+    titles := make([]string, 0, len(values))
+    for title := range values {
+        titles = append(titles, title)
+    }
+    sort.Strings(titles)
+
+    out := gexfAttributes{Class: class}
+    if dynamic {
+        out.Mode = "dynamic"
+    }
+    ids := make(map[string]string, len(titles))
+    for i, title := range titles {
+        id := strconv.Itoa(i)
+        ids[title] = id
+        out.Attributes = append(out.Attributes, gexfAttribute{ID: id, Title: title, Type: propertyType(values[title])})
+    }
+
+    return out, ids
+}
+
+// gexfValuesOf lists the properties as attribute values in key order.
+func gexfValuesOf(props map[string]string, ids map[string]string) []gexfValue {
+
+    if len(props) == 0 {
+        return nil
+    }
+
+    keys := make([]string, 0, len(props))
+    for key := range props {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    out := make([]gexfValue, 0, len(keys))
+    for _, key := range keys {
+        title, start, end, _ := splitTimedKey(key)
+        out = append(out, gexfValue{For: ids[title], Value: props[key], Start: start, End: end})
+    }
+
+    return out
+}
+
+// WriteGEXF writes the graph as GEXF 1.3, the format used by Gephi.
+// Nodes are identified by their "id" property, or by their position if
+// ids are missing or repeated. The "name" property becomes the label,
+// "start" and "end" become the lifetime of a node or edge, and every
+// other property becomes an attribute. A property named like
+// "population@[2000,2010]" is written as the value of the dynamic
+// attribute "population" from 2000 to 2010. Node labels become the
+// "labels" attribute, ":Person:Place". Edges carry their distance as the
+// "distance" attribute and its inverse as their weight, and edges with
+// the property "directed" set to "false" are undirected.
+func (g *Graph) WriteGEXF(w io.Writer) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    ids := g.nodeIDs()
+    idx := g.nodeIndexes()
+    edges := g.edges()
+
+    nodes := make([]gexfNode, len(g.nodes))
+    nodeProps := make([]map[string]string, len(g.nodes))
+    for i, node := range g.nodes {
+        p := node.fileProperties()
+        nodes[i] = gexfNode{ID: ids[i], Label: p["name"], Start: p["start"], End: p["end"]}
+        if p["id"] == ids[i] {
+            delete(p, "id")
+        }
+        delete(p, "name")
+        delete(p, "start")
+        delete(p, "end")
+        nodeProps[i] = p
+    }
+
+    seen := make(map[string]bool, len(edges))
+    positional := false
+    links := make([]gexfEdge, len(edges))
+    edgeProps := make([]map[string]string, len(edges))
+    for i, edge := range edges {
+        p := edge.copyProperties()
+        links[i] = gexfEdge{
+            ID:      p["id"],
+            Source:  ids[idx[edge.ParentNode]],
+            Target:  ids[idx[edge.ChildNode]],
+            Label:   p["name"],
+            Start:   p["start"],
+            End:     p["end"],
+        }
+        positional = positional || len(p["id"]) == 0 || seen[p["id"]]
+        seen[p["id"]] = true
+        if p["directed"] == "false" {
+            links[i].Type = "undirected"
+        }
+        if edge.Distance > 0 {
+            links[i].Weight = strconv.FormatFloat(1/edge.Distance, 'g', -1, 64)
+        }
+        for _, key := range []string{"name", "start", "end", "directed"} {
+            delete(p, key)
+        }
+        p["distance"] = strconv.FormatFloat(edge.Distance, 'g', -1, 64)
+        edgeProps[i] = p
+    }
+
+    // edge ids are kept as an attribute if they are missing or repeated
+    for i := range links {
+        if positional {
+            links[i].ID = "e" + strconv.Itoa(i)
+        } else {
+            delete(edgeProps[i], "id")
+        }
+    }
+
+    nodeAttrs, nodeAttrIDs := gexfAttributesOf(nodeProps, "node")
+    edgeAttrs, edgeAttrIDs := gexfAttributesOf(edgeProps, "edge")
+    for i := range edgeAttrs.Attributes {
+        if edgeAttrs.Attributes[i].Title == "distance" {
+            edgeAttrs.Attributes[i].Type = "double"
+        }
+    }
+
+    dynamic := nodeAttrs.Mode == "dynamic" || edgeAttrs.Mode == "dynamic"
+    for i := range nodes {
+        nodes[i].Values = gexfValuesOf(nodeProps[i], nodeAttrIDs)
+        dynamic = dynamic || len(nodes[i].Start) > 0 || len(nodes[i].End) > 0
+    }
+    for i := range links {
+        links[i].Values = gexfValuesOf(edgeProps[i], edgeAttrIDs)
+        dynamic = dynamic || len(links[i].Start) > 0 || len(links[i].End) > 0
+    }
+
+    doc := gexfDoc{
+        Xmlns:    gexfNamespace,
+        Version:  "1.3",
+        Graph:    gexfGraph{
+            ID:               g.id,
+            DefaultEdgeType:  "directed",
+            Mode:             "static",
+            Attributes:       []gexfAttributes{nodeAttrs, edgeAttrs},
+            Nodes:            nodes,
+            Edges:            links,
+        },
+    }
+    if dynamic {
+        doc.Graph.Mode = "dynamic"
+    }
+
+    if _, err := io.WriteString(w, xml.Header); err != nil {
+        return err
+    }
+    enc := xml.NewEncoder(w)
+    enc.Indent("", "  ")
+    if err := enc.Encode(doc); err != nil {
+        return err
+    }
+    _, err := io.WriteString(w, "\n")
+    return err
+}
+
+// gexfInterval returns the bounds of an element or value, which GEXF
+// gives either as closed or as open bounds.
+func gexfInterval(start string, end string, startOpen string, endOpen string) (string, string) {
+    if len(start) == 0 {
+        start = startOpen
+    }
+    if len(end) == 0 {
+        end = endOpen
+    }
+    return start, end
+}
+
+// ReadGEXF reads a GEXF document, the reverse of WriteGEXF. Attribute
+// values become properties by attribute title, and values of dynamic
+// attributes that hold over an interval are kept as properties named
+// like "population@[2000,2010]". The "distance" edge attribute sets
+// Edge.Distance, otherwise the edge weight is used, and a "labels"
+// attribute such as ":Person:Place" sets the node labels. Spells and
+// visualisation data are ignored.
+func ReadGEXF(r io.Reader) (*Graph, error) {
+
+    var doc gexfDoc
+    if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+        return nil, err
+    }
+
+    titles := map[string]map[string]string{"node": {}, "edge": {}}
+    defaults := map[string]map[string]string{"node": {}, "edge": {}}
+    for _, attrs := range doc.Graph.Attributes {
+        class := attrs.Class
+        if class != "edge" {
+            class = "node"
+        }
+        for _, attr := range attrs.Attributes {
+            titles[class][attr.ID] = attr.Title
+            if attr.Default != nil {
+                defaults[class][attr.Title] = *attr.Default
+            }
+        }
+    }
+
+    setValues := func(props map[string]string, class string, values []gexfValue) {
+        for name, value := range defaults[class] {
+            props[name] = value
+        }
+        for _, v := range values {
+            title, ok := titles[class][v.For]
+            if !ok {
+                title = v.For
+            }
+            start, end := gexfInterval(v.Start, v.End, v.StartOpen, v.EndOpen)
+            if len(start) > 0 || len(end) > 0 {
+                title = timedKey(title, start, end)
+            }
+            props[title] = v.Value
+        }
+    }
+
+    setDefault := func(props map[string]string, key string, value string) {
+        if _, ok := props[key]; !ok && len(value) > 0 {
+            props[key] = value
+        }
+    }
+
+    g := NewGraph(doc.Graph.ID)
+    nodes := make(map[string]*Node, len(doc.Graph.Nodes))
+
+    for _, gn := range doc.Graph.Nodes {
+        n := NewNode()
+        setValues(n.Properties, "node", gn.Values)
+        start, end := gexfInterval(gn.Start, gn.End, gn.StartOpen, gn.EndOpen)
+        setDefault(n.Properties, "id", gn.ID)
+        setDefault(n.Properties, "name", gn.Label)
+        setDefault(n.Properties, "start", start)
+        setDefault(n.Properties, "end", end)
+        n.readLabels()
+        nodes[gn.ID] = n
+        g.insertNode(n)
+    }
+
+    for _, ge := range doc.Graph.Edges {
+        parent, ok := nodes[ge.Source]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + ge.Source)
+        }
+        child, ok := nodes[ge.Target]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + ge.Target)
+        }
+
+        e := NewEdge()
+        setValues(e.Properties, "edge", ge.Values)
+        start, end := gexfInterval(ge.Start, ge.End, ge.StartOpen, ge.EndOpen)
+        setDefault(e.Properties, "id", ge.ID)
+        setDefault(e.Properties, "name", ge.Label)
+        setDefault(e.Properties, "start", start)
+        setDefault(e.Properties, "end", end)
+
+        kind := ge.Type
+        if len(kind) == 0 {
+            kind = doc.Graph.DefaultEdgeType
+        }
+        if kind == "undirected" || kind == "mutual" {
+            e.Properties["directed"] = "false"
+        }
+
+        if value, ok := e.Properties["distance"]; ok {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return nil, err
+            }
+            e.SetDistance(v)
+            delete(e.Properties, "distance")
+        } else if len(ge.Weight) > 0 {
+            v, err := strconv.ParseFloat(ge.Weight, 64)
+            if err != nil {
+                return nil, err
+            }
+            e.SetWeight(v)
+        }
+
+        e.kind = e.Properties["name"]
+        if err := e.Link(parent, child); err != nil {
+            return nil, err
+        }
+    }
+
+    return g, nil
+}
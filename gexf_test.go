@@ -0,0 +1,99 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestGEXFRoundTrip(t *testing.T) {
+
+    g := exchangeGraph(t)
+    g.nodes[2].AddProperty("pop@[2000,2010]", "5")
+    g.nodes[2].AddProperty("pop@[2010,]", "6")
+    g.nodes[0].AddProperty("start", "1990")
+
+    var buf bytes.Buffer
+    if err := g.WriteGEXF(&buf); err != nil {
+        t.Fatal(err)
+    }
+    out := buf.String()
+    for _, want := range []string{
+        `<graph id="sample" defaultedgetype="directed" mode="dynamic">`,
+        `<attributes class="node" mode="dynamic">`,
+        `<node id="a" label="Tom &lt;&amp;&gt; &#34;Q&#34;" start="1990">`,
+        `<attvalue for="1" value="5" start="2000" end="2010"></attvalue>`,
+        `<attvalue for="1" value="6" start="2010"></attvalue>`,
+        `<edge id="e1" source="a" target="b" label="knows" weight="2">`,
+    } {
+        if !strings.Contains(out, want) {
+            t.Errorf("GEXF lacks %s in\n%s", want, out)
+        }
+    }
+
+    h, err := ReadGEXF(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if h.id != "sample" {
+        t.Errorf("graph id %q, want sample", h.id)
+    }
+    sameGraph(t, "GEXF", g, h)
+}
+
+func TestReadGEXF(t *testing.T) {
+
+    doc := `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <graph defaultedgetype="undirected">
+    <attributes class="node">
+      <attribute id="0" title="kind" type="string"><default>city</default></attribute>
+    </attributes>
+    <nodes>
+      <node id="x" label="X"><attvalues><attvalue for="0" value="town" startopen="1" endopen="3"/></attvalues></node>
+      <node id="y"/>
+    </nodes>
+    <edges>
+      <edge source="x" target="y" weight="4"/>
+      <edge id="back" source="y" target="x" type="directed"/>
+    </edges>
+  </graph>
+</gexf>`
+
+    g, err := ReadGEXF(strings.NewReader(doc))
+    if err != nil {
+        t.Fatal(err)
+    }
+    x, _ := g.GetNodeById("x")
+    y, _ := g.GetNodeById("y")
+    if x == nil || y == nil {
+        t.Fatalf("nodes are not found by their GEXF id")
+    }
+    if x.GetProperty("name") != "X" || x.GetProperty("kind") != "city" || x.GetProperty("kind@[1,3]") != "town" ||
+        y.HasProperty("name") || y.GetProperty("kind") != "city" {
+        t.Errorf("node properties %v and %v", x.copyProperties(), y.copyProperties())
+    }
+    if got, want := edgeList(g), "x y 0.25 \ny x 0 "; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        if undirected := e.GetProperty("directed") == "false"; undirected != (e.ParentNode == x) {
+            t.Errorf("edge from %s has directed %q", e.ParentNode.GetProperty("id"), e.GetProperty("directed"))
+        }
+    }
+
+    for _, bad := range []string{
+        `<gexf><graph><nodes><node id="a"/></nodes><edges><edge source="a" target="b"/></edges></graph></gexf>`,
+        `<gexf><graph><nodes><node id="a"/></nodes><edges><edge source="a" target="a" weight="heavy"/></edges></graph></gexf>`,
+        `<gexf><graph>`,
+    } {
+        if _, err := ReadGEXF(strings.NewReader(bad)); err == nil {
+            t.Errorf("reading %s did not fail", bad)
+        }
+    }
+}
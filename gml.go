@@ -0,0 +1,329 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bufio"
+    "errors"
+    "html"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// gmlItem is a key with either a value or a list of items.
+type gmlItem struct {
+    key     string
+    value   string
+    list    []gmlItem
+    isList  bool
+}
+
+// gmlKey reports whether s may be written as a GML key.
+func gmlKey(s string) bool {
+    for i, r := range s {
+        if r > unicode.MaxASCII || !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+            return false
+        }
+    }
+    return len(s) > 0
+}
+
+func gmlQuote(s string) string {
+    s = strings.Replace(s, "&", "&amp;", -1)
+    s = strings.Replace(s, "\"", "&quot;", -1)
+    return "\"" + s + "\""
+}
+
+// gmlProperties writes every property whose key is a valid GML key and
+// not reserved, in key order.
+func gmlProperties(bw *bufio.Writer, props map[string]string, reserved ...string) {
+
+    for _, key := range reserved {
+        delete(props, key)
+    }
+
+    keys := make([]string, 0, len(props))
+    for key := range props {
+        if gmlKey(key) {
+            keys = append(keys, key)
+        }
+    }
+    sort.Strings(keys)
+
+    for _, key := range keys {
+        bw.WriteString("    " + key + " " + gmlQuote(props[key]) + "\n")
+    }
+}
+
+// WriteGML writes the graph in the Graph Modelling Language. Nodes are
+// numbered by position, the "name" property becomes the label, the "id"
+// property is written as "ident", node labels as "labels", such as
+// ":Person:Place", and other properties are written as strings.
+// Properties whose keys are not valid GML keys, or clash with the keys
+// above, are left out. Edges carry their distance as "distance".
+func (g *Graph) WriteGML(w io.Writer) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    idx := g.nodeIndexes()
+    bw := bufio.NewWriter(w)
+
+    bw.WriteString("graph [\n  directed 1\n")
+    if len(g.id) > 0 {
+        bw.WriteString("  id " + gmlQuote(g.id) + "\n")
+    }
+
+    for i, node := range g.nodes {
+        p := node.fileProperties()
+        bw.WriteString("  node [\n    id " + strconv.Itoa(i) + "\n")
+        if name, ok := p["name"]; ok {
+            bw.WriteString("    label " + gmlQuote(name) + "\n")
+        }
+        if id, ok := p["id"]; ok {
+            bw.WriteString("    ident " + gmlQuote(id) + "\n")
+        }
+        gmlProperties(bw, p, "id", "name", "label", "ident")
+        bw.WriteString("  ]\n")
+    }
+
+    for _, edge := range g.edges() {
+        p := edge.copyProperties()
+        bw.WriteString("  edge [\n")
+        bw.WriteString("    source " + strconv.Itoa(idx[edge.ParentNode]) + "\n")
+        bw.WriteString("    target " + strconv.Itoa(idx[edge.ChildNode]) + "\n")
+        if name, ok := p["name"]; ok {
+            bw.WriteString("    label " + gmlQuote(name) + "\n")
+        }
+        bw.WriteString("    distance " + strconv.FormatFloat(edge.Distance, 'g', -1, 64) + "\n")
+        gmlProperties(bw, p, "name", "source", "target", "label", "distance")
+        bw.WriteString("  ]\n")
+    }
+
+    bw.WriteString("]\n")
+    return bw.Flush()
+}
+
+// gmlParse reads a list of items up to a closing bracket or the end of
+// the input.
+func gmlParse(tokens []string, pos int, nested bool) ([]gmlItem, int, error) {
+
+    items := make([]gmlItem, 0)
+    for pos < len(tokens) {
+        key := tokens[pos]
+        if key == "]" {
+            if !nested {
+                return nil, pos, errors.New("Unexpected ] in GML")
+            }
+            return items, pos + 1, nil
+        }
+        if !gmlKey(key) {
+            return nil, pos, errors.New("Invalid GML key: " + key)
+        }
+        if pos+1 >= len(tokens) {
+            return nil, pos, errors.New("Missing value for GML key " + key)
+        }
+
+        value := tokens[pos+1]
+        switch {
+        case value == "[":
+            list, next, err := gmlParse(tokens, pos+2, true)
+            if err != nil {
+                return nil, next, err
+            }
+            items = append(items, gmlItem{key: key, list: list, isList: true})
+            pos = next
+            continue
+        case value == "]":
+            return nil, pos, errors.New("Missing value for GML key " + key)
+        case strings.HasPrefix(value, "\""):
+            value = html.UnescapeString(value[1 : len(value)-1])
+        }
+        items = append(items, gmlItem{key: key, value: value})
+        pos += 2
+    }
+
+    if nested {
+        return nil, pos, errors.New("Unterminated GML list")
+    }
+    return items, pos, nil
+}
+
+// gmlTokens splits GML into keys, values and brackets, dropping comment
+// lines. Strings keep their quotes.
+func gmlTokens(src string) ([]string, error) {
+
+    tokens := make([]string, 0)
+    for i := 0; i < len(src); {
+        c := src[i]
+        switch {
+        case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+            i++
+        case c == '#' && (i == 0 || src[i-1] == '\n'):
+            for i < len(src) && src[i] != '\n' {
+                i++
+            }
+        case c == '[' || c == ']':
+            tokens = append(tokens, string(c))
+            i++
+        case c == '"':
+            end := strings.IndexByte(src[i+1:], '"')
+            if end < 0 {
+                return nil, errors.New("Unterminated GML string")
+            }
+            tokens = append(tokens, src[i:i+end+2])
+            i += end + 2
+        default:
+            start := i
+            for i < len(src) && !strings.ContainsRune(" \t\r\n[]\"", rune(src[i])) {
+                i++
+            }
+            tokens = append(tokens, src[start:i])
+        }
+    }
+
+    return tokens, nil
+}
+
+// gmlFlatten stores the values of items in props, naming the values of
+// nested lists by their path, for example "graphics.x".
+func gmlFlatten(props map[string]string, prefix string, items []gmlItem) {
+    for _, item := range items {
+        if item.isList {
+            gmlFlatten(props, prefix+item.key+".", item.list)
+        } else {
+            props[prefix+item.key] = item.value
+        }
+    }
+}
+
+// ReadGML reads the first graph of a GML file, the reverse of WriteGML.
+// Node and edge keys become properties, with nested lists flattened to
+// dotted names. The node label becomes the "name" property and its
+// "ident", or else its GML id, the "id" property. The edge label becomes
+// the "name" property and "distance" sets Edge.Distance, otherwise a
+// "weight" or "value" key sets the edge weight. A node "labels" key such
+// as ":Person:Place" sets the node labels, and edges of an undirected
+// graph get the property "directed" set to "false".
+func ReadGML(r io.Reader) (*Graph, error) {
+
+    src, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    tokens, err := gmlTokens(string(src))
+    if err != nil {
+        return nil, err
+    }
+    items, _, err := gmlParse(tokens, 0, false)
+    if err != nil {
+        return nil, err
+    }
+
+    var graph *gmlItem
+    for i := range items {
+        if items[i].key == "graph" && items[i].isList {
+            graph = &items[i]
+            break
+        }
+    }
+    if graph == nil {
+        return nil, errors.New("GML file has no graph")
+    }
+
+    g := NewGraph("")
+    directed := false
+    for _, item := range graph.list {
+        if item.key == "directed" {
+            directed = item.value == "1"
+        }
+        if item.key == "id" {
+            g.id = item.value
+        }
+    }
+
+    nodes := make(map[string]*Node)
+    for _, item := range graph.list {
+        if item.key != "node" || !item.isList {
+            continue
+        }
+        n := NewNode()
+        gmlFlatten(n.Properties, "", item.list)
+        id := n.Properties["id"]
+        if _, ok := nodes[id]; ok || len(id) == 0 {
+            return nil, errors.New("GML node ids must be present and unique")
+        }
+        if label, ok := n.Properties["label"]; ok {
+            n.Properties["name"] = label
+            delete(n.Properties, "label")
+        }
+        if ident, ok := n.Properties["ident"]; ok {
+            n.Properties["id"] = ident
+            delete(n.Properties, "ident")
+        }
+        n.readLabels()
+        nodes[id] = n
+        g.insertNode(n)
+    }
+
+    for _, item := range graph.list {
+        if item.key != "edge" || !item.isList {
+            continue
+        }
+        e := NewEdge()
+        gmlFlatten(e.Properties, "", item.list)
+
+        parent, ok := nodes[e.Properties["source"]]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + e.Properties["source"])
+        }
+        child, ok := nodes[e.Properties["target"]]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + e.Properties["target"])
+        }
+        delete(e.Properties, "source")
+        delete(e.Properties, "target")
+
+        if label, ok := e.Properties["label"]; ok {
+            e.Properties["name"] = label
+            delete(e.Properties, "label")
+        }
+        if !directed {
+            e.Properties["directed"] = "false"
+        }
+
+        if value, ok := e.Properties["distance"]; ok {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return nil, err
+            }
+            e.SetDistance(v)
+            delete(e.Properties, "distance")
+        } else {
+            for _, key := range []string{"weight", "value"} {
+                if value, ok := e.Properties[key]; ok {
+                    v, err := strconv.ParseFloat(value, 64)
+                    if err != nil {
+                        return nil, err
+                    }
+                    e.SetWeight(v)
+                    break
+                }
+            }
+        }
+
+        e.kind = e.Properties["name"]
+        if err := e.Link(parent, child); err != nil {
+            return nil, err
+        }
+    }
+
+    return g, nil
+}
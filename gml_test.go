@@ -0,0 +1,118 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestWriteGML(t *testing.T) {
+
+    g := NewGraph("g")
+    a, _ := g.AddNode("a", `Tom & "Q"`, "Person")
+    b, _ := g.AddNode("b", "B")
+    b.AddProperty("not a key", "x")
+    b.AddProperty("source", "web")
+    g.AddEdge("ab", "knows", 0.5, a, b)
+
+    var buf bytes.Buffer
+    if err := g.WriteGML(&buf); err != nil {
+        t.Fatal(err)
+    }
+    want := `graph [
+  directed 1
+  id "g"
+  node [
+    id 0
+    label "Tom &amp; &quot;Q&quot;"
+    ident "a"
+    labels ":Person"
+  ]
+  node [
+    id 1
+    label "B"
+    ident "b"
+    source "web"
+  ]
+  edge [
+    source 0
+    target 1
+    label "knows"
+    distance 2
+    id "ab"
+  ]
+]
+`
+    if got := buf.String(); got != want {
+        t.Errorf("GML\n%s\nwant\n%s", got, want)
+    }
+}
+
+func TestGMLRoundTrip(t *testing.T) {
+
+    g := exchangeGraph(t)
+    var buf bytes.Buffer
+    if err := g.WriteGML(&buf); err != nil {
+        t.Fatal(err)
+    }
+    h, err := ReadGML(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if h.id != "sample" {
+        t.Errorf("graph id %q, want sample", h.id)
+    }
+    sameGraph(t, "GML", g, h)
+}
+
+func TestReadGML(t *testing.T) {
+
+    src := `# written by hand
+Creator "test"
+graph [
+  node [ id 1 label "One" graphics [ x 1.5 y -2 ] ]
+  node [ id 2 ]
+  edge [ source 1 target 2 value 4 ]
+  edge [ source 2 target 1 weight 2 label "back" ]
+]`
+
+    g, err := ReadGML(strings.NewReader(src))
+    if err != nil {
+        t.Fatal(err)
+    }
+    one, _ := g.GetNodeById("1")
+    if one == nil || len(g.nodes) != 2 {
+        t.Fatalf("%d nodes, want 2 with ids 1 and 2", len(g.nodes))
+    }
+    if one.GetProperty("name") != "One" || one.GetProperty("graphics.x") != "1.5" || one.GetProperty("graphics.y") != "-2" {
+        t.Errorf("node properties %v", one.copyProperties())
+    }
+    if got, want := edgeList(g), "1 2 0.25 \n2 1 0.5 back"; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        if e.GetProperty("directed") != "false" {
+            t.Errorf("edge of an undirected graph is directed")
+        }
+    }
+
+    for _, bad := range []string{
+        `node [ id 1 ]`,
+        `graph [ node [ id 1 ] node [ id 1 ] ]`,
+        `graph [ node [ label "no id" ] ]`,
+        `graph [ node [ id 1 ] edge [ source 1 target 2 ] ]`,
+        `graph [ node [ id 1 ] edge [ source 1 target 1 distance far ] ]`,
+        `graph [ node [ id 1 ]`,
+        `graph [ label "open ]`,
+        `graph ] [`,
+    } {
+        if _, err := ReadGML(strings.NewReader(bad)); err == nil {
+            t.Errorf("reading %s did not fail", bad)
+        }
+    }
+}
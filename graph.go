@@ -7,11 +7,14 @@ package graph
 
 import (
     "errors"
+    "strconv"
 )
 
 type Graph struct {
-    nodes []*Node
-    id    string
+    nodes  []*Node
+    labels map[string][]*Node
+    schema *Schema
+    id     string
 }
 
 type Path struct {
@@ -35,9 +38,10 @@ func (g *Graph) NumNodes() int {
     return len(g.nodes)
 }
 
-// AddNode creates a new node object and adds it to the graph.
-// A pointer to the newly created node is returned.
-func (g *Graph) AddNode(id string, name string) (*Node, error) {
+// AddNode creates a new node object with the given labels and
+// adds it to the graph. A pointer to the newly created node is
+// returned. It fails if the graph schema does not allow the node.
+func (g *Graph) AddNode(id string, name string, labels ...string) (*Node, error) {
     if g == nil {
         return nil, errors.New("Graph is nil")
     }
@@ -49,14 +53,33 @@ func (g *Graph) AddNode(id string, name string) (*Node, error) {
     n := NewNode()
     n.AddProperty("id", id)
     n.AddProperty("name", name)
-    n.index = len(g.nodes)
+    for _, label := range labels {
+        n.addLabel(label)
+    }
+    if g.schema != nil {
+        if errs := g.schema.nodeErrors(n); len(errs) > 0 {
+            return nil, errs[0]
+        }
+    }
     //add to graph
-    g.nodes = append(g.nodes, n)
+    g.insertNode(n)
     
     return n, nil
 }
 
-// AddEdge creates a new edge and adds it to the input and output nodes
+// insertNode adds an existing node object to the graph.
+func (g *Graph) insertNode(n *Node) {
+    n.index = len(g.nodes)
+    n.graph = g
+    g.nodes = append(g.nodes, n)
+    for _, label := range n.Labels() {
+        g.indexLabel(n, label)
+    }
+}
+
+// AddEdge creates a new edge and adds it to the input and output nodes.
+// The edge name is also its type. It fails if the graph schema does not
+// allow the edge.
 func (g *Graph) AddEdge(id string, name string, weight float64, in *Node, out *Node) error {
     if g == nil {
         return errors.New("Graph is nil")
@@ -73,8 +96,9 @@ func (g *Graph) AddEdge(id string, name string, weight float64, in *Node, out *N
     e.AddProperty("id", id)
     e.AddProperty("name", name)
     e.SetWeight(weight)
+    e.kind = name
     
-    //set link and return any error
+    //set link and return any error, Link checks the schema
     return e.Link(in, out)
 }
 
@@ -184,9 +208,73 @@ func (g *Graph) RemoveNode(n *Node) {
     copy(g.nodes[gI:], g.nodes[gI+1:])
     g.nodes[len(g.nodes)-1] = nil
     g.nodes = g.nodes[:len(g.nodes)-1]
+    for _, label := range n.Labels() {
+        g.unindexLabel(n, label)
+    }
+    n.graph = nil
     
     //destroy node
     n = nil
     
     return
 }
+
+// nodeIndexes returns the position of every node within the graph.
+// Node.index is not kept up to date by RemoveNode, so algorithms
+// that need dense indexes should use this instead.
+func (g *Graph) nodeIndexes() map[*Node]int {
+    idx := make(map[*Node]int, len(g.nodes))
+    for i, node := range g.nodes {
+        idx[node] = i
+    }
+    return idx
+}
+
+// nodeIDs returns the "id" property of every node if each node has one
+// and no two are the same, otherwise "n" followed by the node position.
+// Formats that refer to nodes by name use these as identifiers.
+func (g *Graph) nodeIDs() []string {
+
+    ids := make([]string, len(g.nodes))
+    seen := make(map[string]bool, len(g.nodes))
+    for i, node := range g.nodes {
+        ids[i] = node.GetProperty("id")
+        if len(ids[i]) == 0 || seen[ids[i]] {
+            for j := range ids {
+                ids[j] = "n" + strconv.Itoa(j)
+            }
+            break
+        }
+        seen[ids[i]] = true
+    }
+
+    return ids
+}
+
+// edges returns every edge linking two nodes of the graph exactly once.
+func (g *Graph) edges() []*Edge {
+    
+    out := make([]*Edge, 0)
+    seen := make(map[*Edge]bool)
+    idx := g.nodeIndexes()
+    
+    for _, node := range g.nodes {
+        for _, edge := range node.Edges {
+            if edge == nil || edge.ParentNode != node {
+                continue
+            }
+            // a self loop is listed twice on its node
+            if edge.ChildNode == node {
+                if seen[edge] {
+                    continue
+                }
+                seen[edge] = true
+            } else if _, ok := idx[edge.ChildNode]; !ok {
+                continue
+            }
+            out = append(out, edge)
+        }
+    }
+    
+    return out
+}
@@ -0,0 +1,317 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "encoding/xml"
+    "errors"
+    "io"
+    "sort"
+    "strconv"
+)
+
+const graphmlNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphmlDoc struct {
+    XMLName  xml.Name        `xml:"graphml"`
+    Xmlns    string          `xml:"xmlns,attr,omitempty"`
+    Keys     []graphmlKey    `xml:"key"`
+    Graphs   []graphmlGraph  `xml:"graph"`
+}
+
+type graphmlKey struct {
+    ID       string   `xml:"id,attr"`
+    For      string   `xml:"for,attr"`
+    Name     string   `xml:"attr.name,attr,omitempty"`
+    Type     string   `xml:"attr.type,attr,omitempty"`
+    Default  *string  `xml:"default"`
+}
+
+type graphmlGraph struct {
+    ID           string         `xml:"id,attr,omitempty"`
+    EdgeDefault  string         `xml:"edgedefault,attr"`
+    Nodes        []graphmlNode  `xml:"node"`
+    Edges        []graphmlEdge  `xml:"edge"`
+}
+
+type graphmlNode struct {
+    ID    string         `xml:"id,attr"`
+    Data  []graphmlData  `xml:"data"`
+}
+
+type graphmlEdge struct {
+    ID        string         `xml:"id,attr,omitempty"`
+    Source    string         `xml:"source,attr"`
+    Target    string         `xml:"target,attr"`
+    Directed  string         `xml:"directed,attr,omitempty"`
+    Data      []graphmlData  `xml:"data"`
+}
+
+type graphmlData struct {
+    Key    string  `xml:"key,attr"`
+    Value  string  `xml:",chardata"`
+}
+
+// propertyType returns the narrowest GraphML type able to hold every
+// value: "boolean", "long", "double" or "string".
+func propertyType(values []string) string {
+
+    types := []string{"boolean", "long", "double"}
+    for _, value := range values {
+        for len(types) > 0 {
+            var err error
+            switch types[0] {
+            case "boolean":
+                if value != "true" && value != "false" {
+                    err = strconv.ErrSyntax
+                }
+            case "long":
+                _, err = strconv.ParseInt(value, 10, 64)
+            case "double":
+                _, err = strconv.ParseFloat(value, 64)
+            }
+            if err == nil {
+                break
+            }
+            types = types[1:]
+        }
+    }
+
+    if len(types) == 0 {
+        return "string"
+    }
+    return types[0]
+}
+
+// graphmlKeys declares a key for every property found in props, with
+// ids made of prefix and a number.
+func graphmlKeys(props []map[string]string, kind string, prefix string) ([]graphmlKey, map[string]string) {
+
+    values := make(map[string][]string)
+    for _, p := range props {
+        for key, value := range p {
+            values[key] = append(values[key], value)
+        }
+    }
+
+    names := make([]string, 0, len(values))
+    for name := range values {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    keys := make([]graphmlKey, 0, len(names))
+    ids := make(map[string]string, len(names))
+    for i, name := range names {
+        id := prefix + strconv.Itoa(i)
+        ids[name] = id
+        keys = append(keys, graphmlKey{ID: id, For: kind, Name: name, Type: propertyType(values[name])})
+    }
+
+    return keys, ids
+}
+
+// graphmlDataOf lists the properties as data elements in key order.
+func graphmlDataOf(props map[string]string, ids map[string]string) []graphmlData {
+
+    names := make([]string, 0, len(props))
+    for name := range props {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    out := make([]graphmlData, 0, len(names))
+    for _, name := range names {
+        out = append(out, graphmlData{Key: ids[name], Value: props[name]})
+    }
+
+    return out
+}
+
+// WriteGraphML writes the graph as GraphML. Node and edge properties
+// become data keys typed from their values, and every edge carries its
+// distance under the "distance" key. Node labels are written under the
+// "labels" key as Neo4j does, ":Person:Place". Edges with the property
+// "directed" set to "false" are written as undirected edges.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    idx := g.nodeIndexes()
+    edges := g.edges()
+
+    nodeProps := make([]map[string]string, len(g.nodes))
+    for i, node := range g.nodes {
+        nodeProps[i] = node.fileProperties()
+    }
+
+    edgeProps := make([]map[string]string, len(edges))
+    undirected := make([]bool, len(edges))
+    for i, edge := range edges {
+        p := edge.copyProperties()
+        undirected[i] = p["directed"] == "false"
+        delete(p, "directed")
+        p["distance"] = strconv.FormatFloat(edge.Distance, 'g', -1, 64)
+        edgeProps[i] = p
+    }
+
+    nodeKeys, nodeIDs := graphmlKeys(nodeProps, "node", "n")
+    edgeKeys, edgeIDs := graphmlKeys(edgeProps, "edge", "e")
+    for i := range edgeKeys {
+        if edgeKeys[i].Name == "distance" {
+            edgeKeys[i].Type = "double"
+        }
+    }
+
+    doc := graphmlDoc{
+        Xmlns:   graphmlNamespace,
+        Keys:    append(nodeKeys, edgeKeys...),
+        Graphs:  []graphmlGraph{{ID: g.id, EdgeDefault: "directed"}},
+    }
+    graph := &doc.Graphs[0]
+
+    for i := range g.nodes {
+        graph.Nodes = append(graph.Nodes, graphmlNode{
+            ID:    "n" + strconv.Itoa(i),
+            Data:  graphmlDataOf(nodeProps[i], nodeIDs),
+        })
+    }
+
+    for i, edge := range edges {
+        e := graphmlEdge{
+            ID:      "e" + strconv.Itoa(i),
+            Source:  "n" + strconv.Itoa(idx[edge.ParentNode]),
+            Target:  "n" + strconv.Itoa(idx[edge.ChildNode]),
+            Data:    graphmlDataOf(edgeProps[i], edgeIDs),
+        }
+        if undirected[i] {
+            e.Directed = "false"
+        }
+        graph.Edges = append(graph.Edges, e)
+    }
+
+    if _, err := io.WriteString(w, xml.Header); err != nil {
+        return err
+    }
+    enc := xml.NewEncoder(w)
+    enc.Indent("", "  ")
+    if err := enc.Encode(doc); err != nil {
+        return err
+    }
+    _, err := io.WriteString(w, "\n")
+    return err
+}
+
+// ReadGraphML reads the first graph of a GraphML document. Data keys
+// become node and edge properties by attribute name. The "distance" edge
+// key sets Edge.Distance, otherwise a "weight" key sets the edge weight.
+// Nodes without an "id" property are given their GraphML id, a "labels"
+// key such as ":Person:Place" sets the node labels, and undirected edges
+// get the property "directed" set to "false".
+func ReadGraphML(r io.Reader) (*Graph, error) {
+
+    var doc graphmlDoc
+    if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+        return nil, err
+    }
+    if len(doc.Graphs) == 0 {
+        return nil, errors.New("GraphML document has no graph")
+    }
+
+    names := make(map[string]string)
+    nodeDefaults := make(map[string]string)
+    edgeDefaults := make(map[string]string)
+    for _, key := range doc.Keys {
+        name := key.Name
+        if len(name) == 0 {
+            name = key.ID
+        }
+        names[key.ID] = name
+        if key.Default == nil {
+            continue
+        }
+        if key.For == "node" || key.For == "all" {
+            nodeDefaults[name] = *key.Default
+        }
+        if key.For == "edge" || key.For == "all" {
+            edgeDefaults[name] = *key.Default
+        }
+    }
+
+    gm := doc.Graphs[0]
+    g := NewGraph(gm.ID)
+    nodes := make(map[string]*Node, len(gm.Nodes))
+
+    for _, gn := range gm.Nodes {
+        n := NewNode()
+        for name, value := range nodeDefaults {
+            n.Properties[name] = value
+        }
+        for _, d := range gn.Data {
+            n.Properties[names[d.Key]] = d.Value
+        }
+        if len(n.Properties["id"]) == 0 {
+            n.Properties["id"] = gn.ID
+        }
+        n.readLabels()
+        nodes[gn.ID] = n
+        g.insertNode(n)
+    }
+
+    for _, ge := range gm.Edges {
+        parent, ok := nodes[ge.Source]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + ge.Source)
+        }
+        child, ok := nodes[ge.Target]
+        if !ok {
+            return nil, errors.New("Edge refers to a missing node: " + ge.Target)
+        }
+
+        e := NewEdge()
+        for name, value := range edgeDefaults {
+            e.Properties[name] = value
+        }
+        for _, d := range ge.Data {
+            e.Properties[names[d.Key]] = d.Value
+        }
+        if len(ge.ID) > 0 && len(e.Properties["id"]) == 0 {
+            e.Properties["id"] = ge.ID
+        }
+
+        directed := gm.EdgeDefault != "undirected"
+        if len(ge.Directed) > 0 {
+            directed = ge.Directed == "true"
+        }
+        if !directed {
+            e.Properties["directed"] = "false"
+        }
+
+        if value, ok := e.Properties["distance"]; ok {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return nil, err
+            }
+            e.SetDistance(v)
+            delete(e.Properties, "distance")
+        } else if value, ok := e.Properties["weight"]; ok {
+            v, err := strconv.ParseFloat(value, 64)
+            if err != nil {
+                return nil, err
+            }
+            e.SetWeight(v)
+        }
+
+        e.kind = e.Properties["name"]
+        if err := e.Link(parent, child); err != nil {
+            return nil, err
+        }
+    }
+
+    return g, nil
+}
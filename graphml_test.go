@@ -0,0 +1,95 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestGraphMLRoundTrip(t *testing.T) {
+
+    g := exchangeGraph(t)
+    g.nodes[0].AddProperty("age", "42")
+    g.nodes[1].AddProperty("age", "7.5")
+
+    var buf bytes.Buffer
+    if err := g.WriteGraphML(&buf); err != nil {
+        t.Fatal(err)
+    }
+    out := buf.String()
+    for _, want := range []string{
+        `<key id="n0" for="node" attr.name="age" attr.type="double"></key>`,
+        `<key id="n1" for="node" attr.name="city" attr.type="string"></key>`,
+        `<data key="n3">Tom &lt;&amp;&gt; &#34;Q&#34;</data>`,
+        `<edge id="e3" source="n2" target="n2">`,
+    } {
+        if !strings.Contains(out, want) {
+            t.Errorf("GraphML lacks %s in\n%s", want, out)
+        }
+    }
+
+    h, err := ReadGraphML(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if h.id != "sample" {
+        t.Errorf("graph id %q, want sample", h.id)
+    }
+    sameGraph(t, "GraphML", g, h)
+}
+
+func TestReadGraphML(t *testing.T) {
+
+    doc := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="color" attr.type="string"><default>red</default></key>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d2" for="all" attr.type="string"/>
+  <graph id="G" edgedefault="undirected">
+    <node id="x"><data key="d0">blue</data></node>
+    <node id="y"><data key="d2">extra</data></node>
+    <edge source="x" target="y"><data key="d1">4</data></edge>
+    <edge id="back" source="y" target="x" directed="true"/>
+  </graph>
+</graphml>`
+
+    g, err := ReadGraphML(strings.NewReader(doc))
+    if err != nil {
+        t.Fatal(err)
+    }
+    x, _ := g.GetNodeById("x")
+    y, _ := g.GetNodeById("y")
+    if x == nil || y == nil {
+        t.Fatalf("nodes are not found by their GraphML id")
+    }
+    // defaults apply where data is missing, keys without a name use the id
+    if x.GetProperty("color") != "blue" || y.GetProperty("color") != "red" || y.GetProperty("d2") != "extra" {
+        t.Errorf("node properties %v and %v", x.copyProperties(), y.copyProperties())
+    }
+    if got, want := edgeList(g), "x y 0.25 \ny x 0 "; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        undirected := e.GetProperty("directed") == "false"
+        if undirected != (e.ParentNode == x) {
+            t.Errorf("edge from %s has directed %q", e.ParentNode.GetProperty("id"), e.GetProperty("directed"))
+        }
+    }
+
+    for _, bad := range []string{
+        `<graphml></graphml>`,
+        `<graphml><graph><node id="a"/><edge source="a" target="b"/></graph></graphml>`,
+        `<graphml><key id="w" for="edge" attr.name="distance"/><graph><node id="a"/>` +
+            `<edge source="a" target="a"><data key="w">far</data></edge></graph></graphml>`,
+        `<graphml><graph>`,
+    } {
+        if _, err := ReadGraphML(strings.NewReader(bad)); err == nil {
+            t.Errorf("reading %s did not fail", bad)
+        }
+    }
+}
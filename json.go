@@ -0,0 +1,165 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+// A graph is written to JSON as follows:
+//
+//  {
+//      "id": "graph id",
+//      "nodes": [
+//          {"labels": ["Person"], "properties": {"id": "1", "name": "Tom"}},
+//          {"properties": {"id": "2", "name": "Bob"}}
+//      ],
+//      "edges": [
+//          {"parent": 0, "child": 1, "distance": 1, "type": "knows",
+//           "properties": {"id": "1", "name": "knows"}}
+//      ]
+//  }
+//
+// Nodes are listed in the order they were added. Edges refer to their
+// parent and child node by position in the nodes list, so node
+// properties, including "id", do not need to be unique. Edges to nodes
+// that are not part of the graph are left out. Node labels and edge
+// types are left out when empty.
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+)
+
+type jsonGraph struct {
+    ID     string      `json:"id"`
+    Nodes  []jsonNode  `json:"nodes"`
+    Edges  []jsonEdge  `json:"edges"`
+}
+
+type jsonNode struct {
+    Labels      []string           `json:"labels,omitempty"`
+    Properties  map[string]string  `json:"properties"`
+}
+
+type jsonEdge struct {
+    Parent      int                `json:"parent"`
+    Child       int                `json:"child"`
+    Distance    float64            `json:"distance"`
+    Type        string             `json:"type,omitempty"`
+    Properties  map[string]string  `json:"properties"`
+}
+
+// MarshalJSON encodes the graph, see the schema above.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    idx := g.nodeIndexes()
+    out := jsonGraph{
+        ID:     g.id,
+        Nodes:  make([]jsonNode, len(g.nodes)),
+        Edges:  make([]jsonEdge, 0),
+    }
+
+    for i, node := range g.nodes {
+        out.Nodes[i] = jsonNode{Labels: node.Labels(), Properties: node.copyProperties()}
+    }
+
+    for _, edge := range g.edges() {
+        out.Edges = append(out.Edges, jsonEdge{
+            Parent:      idx[edge.ParentNode],
+            Child:       idx[edge.ChildNode],
+            Distance:    edge.Distance,
+            Type:        edge.Type(),
+            Properties:  edge.copyProperties(),
+        })
+    }
+
+    return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the graph with the one encoded in data. If the
+// graph has a schema the decoded graph must conform to it, otherwise it
+// fails with SchemaErrors and the graph is left as it was.
+func (g *Graph) UnmarshalJSON(data []byte) error {
+
+    if g == nil {
+        return errors.New("Graph is nil")
+    }
+
+    var in jsonGraph
+    if err := json.Unmarshal(data, &in); err != nil {
+        return err
+    }
+
+    nodes := make([]*Node, len(in.Nodes))
+    for i, jn := range in.Nodes {
+        n := NewNode()
+        for key, value := range jn.Properties {
+            n.Properties[key] = value
+        }
+        for _, label := range jn.Labels {
+            n.AddLabel(label)
+        }
+        nodes[i] = n
+    }
+
+    for _, je := range in.Edges {
+        if je.Parent < 0 || je.Parent >= len(nodes) || je.Child < 0 || je.Child >= len(nodes) {
+            return errors.New("Edge refers to a missing node")
+        }
+        e := NewEdge()
+        for key, value := range je.Properties {
+            e.Properties[key] = value
+        }
+        e.SetDistance(je.Distance)
+        e.kind = je.Type
+        if err := e.Link(nodes[je.Parent], nodes[je.Child]); err != nil {
+            return err
+        }
+    }
+
+    if g.schema != nil {
+        if errs := g.schema.validate(&Graph{nodes: nodes}); len(errs) > 0 {
+            return errs
+        }
+    }
+
+    g.id = in.ID
+    for _, n := range g.nodes {
+        n.graph = nil
+    }
+    g.nodes = make([]*Node, 0, len(nodes))
+    g.labels = nil
+    for _, n := range nodes {
+        g.insertNode(n)
+    }
+
+    return nil
+}
+
+// Encode writes the graph as JSON to w.
+func (g *Graph) Encode(w io.Writer) error {
+
+    data, err := g.MarshalJSON()
+    if err != nil {
+        return err
+    }
+
+    _, err = w.Write(data)
+    return err
+}
+
+// Decode reads a graph written by Encode from r.
+func Decode(r io.Reader) (*Graph, error) {
+
+    g := NewGraph("")
+    if err := json.NewDecoder(r).Decode(g); err != nil {
+        return nil, err
+    }
+
+    return g, nil
+}
@@ -0,0 +1,126 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "sort"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// exchangeGraph is a small graph with text that needs escaping in most
+// formats, parallel edges and a self loop.
+func exchangeGraph(t *testing.T) *Graph {
+
+    g := NewGraph("sample")
+    a, _ := g.AddNode("a", `Tom <&> "Q"`)
+    b, _ := g.AddNode("b", "Zoë, Jr.")
+    c, _ := g.AddNode("c", "c")
+    c.AddProperty("city", "Paris")
+
+    edges := []struct {
+        id        string
+        parent    *Node
+        child     *Node
+        distance  float64
+    }{{"e1", a, b, 0.5}, {"e2", a, b, 2}, {"e3", c, c, 1}, {"e4", b, c, 1.5}}
+    for _, edge := range edges {
+        e := NewEdge()
+        e.AddProperty("id", edge.id)
+        e.AddProperty("name", "knows")
+        e.SetDistance(edge.distance)
+        e.kind = "knows"
+        if err := e.Link(edge.parent, edge.child); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return g
+}
+
+// edgeList lists the edges of g as "parent child distance name" lines
+// in sorted order, with nodes named by id.
+func edgeList(g *Graph) string {
+
+    lines := make([]string, 0)
+    for _, e := range g.edges() {
+        lines = append(lines, e.ParentNode.GetProperty("id")+" "+e.ChildNode.GetProperty("id")+" "+
+            strconv.FormatFloat(e.Distance, 'g', -1, 64)+" "+e.GetProperty("name"))
+    }
+    sort.Strings(lines)
+    return strings.Join(lines, "\n")
+}
+
+// sameGraph reports differences in the node properties and edges of the
+// graph h read back from the format name and the graph g written to it.
+func sameGraph(t *testing.T, name string, g *Graph, h *Graph) {
+
+    t.Helper()
+    if len(h.nodes) != len(g.nodes) {
+        t.Errorf("%s: %d nodes, want %d", name, len(h.nodes), len(g.nodes))
+        return
+    }
+    for _, n := range g.nodes {
+        m, ok := h.GetNodeById(n.GetProperty("id"))
+        if !ok {
+            t.Errorf("%s: node %s is missing", name, n.GetProperty("id"))
+            continue
+        }
+        for key, value := range n.copyProperties() {
+            if got := m.GetProperty(key); got != value {
+                t.Errorf("%s: node %s property %s is %q, want %q", name, n.GetProperty("id"), key, got, value)
+            }
+        }
+    }
+    if got, want := edgeList(h), edgeList(g); got != want {
+        t.Errorf("%s: edges\n%s\nwant\n%s", name, got, want)
+    }
+}
+
+func TestJSON(t *testing.T) {
+
+    g := NewGraph("g")
+    a, _ := g.AddNode("1", "Tom", "Person")
+    b, _ := g.AddNode("2", "Bob")
+    g.AddEdge("3", "knows", 0.5, a, b)
+
+    var buf bytes.Buffer
+    if err := g.Encode(&buf); err != nil {
+        t.Fatal(err)
+    }
+    want := `{"id":"g","nodes":[{"labels":["Person"],"properties":{"id":"1","name":"Tom"}},` +
+        `{"properties":{"id":"2","name":"Bob"}}],"edges":[{"parent":0,"child":1,"distance":2,` +
+        `"type":"knows","properties":{"id":"3","name":"knows"}}]}`
+    if got := buf.String(); got != want {
+        t.Errorf("encoded\n%s\nwant\n%s", got, want)
+    }
+
+    g = exchangeGraph(t)
+    buf.Reset()
+    if err := g.Encode(&buf); err != nil {
+        t.Fatal(err)
+    }
+    h, err := Decode(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if h.id != "sample" {
+        t.Errorf("graph id %q, want sample", h.id)
+    }
+    sameGraph(t, "JSON", g, h)
+
+    for _, bad := range []string{
+        `{"nodes":[{"properties":{}}],"edges":[{"parent":0,"child":1}]}`,
+        `{"nodes":[{"properties":{}}],"edges":[{"parent":-1,"child":0}]}`,
+        `{"nodes":`,
+    } {
+        if _, err := Decode(strings.NewReader(bad)); err == nil {
+            t.Errorf("decoding %s did not fail", bad)
+        }
+    }
+}
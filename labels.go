@@ -0,0 +1,480 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+    "sort"
+    "strings"
+)
+
+// Direction selects which edges of a node a traversal follows.
+type Direction int
+
+const (
+    // Outgoing follows edges from their parent to their child node.
+    Outgoing Direction = iota
+    // Incoming follows edges from their child to their parent node.
+    Incoming
+    // Both follows edges either way.
+    Both
+)
+
+// TraversalFilter limits the edges and nodes a traversal visits. Only
+// edges with one of EdgeTypes and nodes with one of NodeLabels are
+// followed; an empty list allows any. MaxDepth limits the number of
+// edges from the start node, 0 for no limit.
+type TraversalFilter struct {
+    EdgeTypes   []string
+    NodeLabels  []string
+    Direction   Direction
+    MaxDepth    int
+}
+
+// AddLabel adds a label to the node. Empty labels are ignored. It fails
+// if the graph schema does not allow the node with the label.
+func (n *Node) AddLabel(label string) error {
+
+    if n == nil {
+        return errors.New("Node is nil")
+    }
+
+    if !n.addLabel(label) {
+        return nil
+    }
+    if s := n.schema(); s != nil {
+        if err := s.checkAround(n); err != nil {
+            n.removeLabel(label)
+            return err
+        }
+    }
+
+    return nil
+}
+
+// RemoveLabel removes a label from the node. It fails if the graph
+// schema does not allow the node without the label.
+func (n *Node) RemoveLabel(label string) error {
+
+    if n == nil {
+        return errors.New("Node is nil")
+    }
+
+    if !n.removeLabel(label) {
+        return nil
+    }
+    if s := n.schema(); s != nil {
+        if err := s.checkAround(n); err != nil {
+            n.addLabel(label)
+            return err
+        }
+    }
+
+    return nil
+}
+
+// addLabel adds a label to the node and the label index of its graph,
+// returning false if the node already has it.
+func (n *Node) addLabel(label string) bool {
+
+    if len(label) == 0 {
+        return false
+    }
+
+    n.lock.Lock()
+    i := sort.SearchStrings(n.labels, label)
+    if i < len(n.labels) && n.labels[i] == label {
+        n.lock.Unlock()
+        return false
+    }
+    n.labels = append(n.labels, "")
+    copy(n.labels[i+1:], n.labels[i:])
+    n.labels[i] = label
+    g := n.graph
+    n.lock.Unlock()
+
+    if g != nil {
+        g.indexLabel(n, label)
+    }
+    return true
+}
+
+// removeLabel removes a label from the node and the label index of its
+// graph, returning false if the node does not have it.
+func (n *Node) removeLabel(label string) bool {
+
+    n.lock.Lock()
+    i := sort.SearchStrings(n.labels, label)
+    if i == len(n.labels) || n.labels[i] != label {
+        n.lock.Unlock()
+        return false
+    }
+    n.labels = append(n.labels[:i], n.labels[i+1:]...)
+    g := n.graph
+    n.lock.Unlock()
+
+    if g != nil {
+        g.unindexLabel(n, label)
+    }
+    return true
+}
+
+// HasLabel returns true if the node has the label.
+func (n *Node) HasLabel(label string) bool {
+
+    if n == nil {
+        return false
+    }
+
+    n.lock.RLock()
+    defer n.lock.RUnlock()
+
+    i := sort.SearchStrings(n.labels, label)
+    return i < len(n.labels) && n.labels[i] == label
+}
+
+// Labels returns the labels of the node in sorted order.
+func (n *Node) Labels() []string {
+
+    if n == nil {
+        return nil
+    }
+
+    n.lock.RLock()
+    defer n.lock.RUnlock()
+
+    return append([]string{}, n.labels...)
+}
+
+// fileProperties returns the properties the file formats write for the
+// node, with its labels under "labels" as Neo4j writes them in GraphML,
+// ":Person:Place".
+func (n *Node) fileProperties() map[string]string {
+
+    props := n.copyProperties()
+    if labels := n.Labels(); len(labels) > 0 {
+        props["labels"] = ":" + strings.Join(labels, ":")
+    }
+
+    return props
+}
+
+// readLabels turns a "labels" property written by fileProperties back
+// into labels. Other "labels" properties are kept as they are.
+func (n *Node) readLabels() {
+
+    text := n.Properties["labels"]
+    if !strings.HasPrefix(text, ":") {
+        return
+    }
+    delete(n.Properties, "labels")
+    for _, label := range strings.Split(text[1:], ":") {
+        n.addLabel(label)
+    }
+}
+
+// EdgesOfType returns the edges of the node with any of the given
+// types, grouped by type in the order given.
+func (n *Node) EdgesOfType(types ...string) []*Edge {
+
+    out := make([]*Edge, 0)
+
+    if n == nil {
+        return out
+    }
+
+    n.lock.RLock()
+    defer n.lock.RUnlock()
+
+    for i, t := range types {
+        if containsString(types[:i], t) {
+            continue
+        }
+        out = append(out, n.edgeTypes[t]...)
+    }
+
+    return out
+}
+
+// indexEdge adds an edge to the type index of the node. The caller
+// holds the node lock.
+func (n *Node) indexEdge(e *Edge, t string) {
+    if n.edgeTypes == nil {
+        n.edgeTypes = make(map[string][]*Edge)
+    }
+    n.edgeTypes[t] = append(n.edgeTypes[t], e)
+}
+
+// unindexEdge removes one entry of an edge from the type index of the
+// node. The caller holds the node lock.
+func (n *Node) unindexEdge(e *Edge, t string) {
+
+    list := n.edgeTypes[t]
+    for i, edge := range list {
+        if edge == e {
+            list = append(list[:i], list[i+1:]...)
+            break
+        }
+    }
+
+    if len(list) == 0 {
+        delete(n.edgeTypes, t)
+    } else {
+        n.edgeTypes[t] = list
+    }
+}
+
+// SetType sets the relationship type of the edge and moves it within
+// the type index of its nodes. It fails if the graph schema does not
+// allow the edge with the type.
+func (e *Edge) SetType(t string) error {
+
+    if e == nil {
+        return errors.New("Edge is nil")
+    }
+
+    e.lock.RLock()
+    parent, child, old := e.ParentNode, e.ChildNode, e.kind
+    e.lock.RUnlock()
+
+    if old == t {
+        return nil
+    }
+    if parent != nil && child != nil {
+        if s := parent.schema(); s != nil {
+            if err := s.allowEdge(e, t, parent, child); err != nil {
+                return err
+            }
+        }
+    }
+
+    e.lock.Lock()
+    defer e.lock.Unlock()
+
+    old = e.kind
+    e.kind = t
+
+    // a self loop is indexed twice on its node, once per end
+    for _, n := range []*Node{e.ParentNode, e.ChildNode} {
+        if n == nil {
+            continue
+        }
+        n.lock.Lock()
+        for _, edge := range n.edgeTypes[old] {
+            if edge == e {
+                n.unindexEdge(e, old)
+                n.indexEdge(e, t)
+                break
+            }
+        }
+        n.lock.Unlock()
+    }
+
+    return nil
+}
+
+// Type returns the relationship type of the edge.
+func (e *Edge) Type() string {
+
+    if e == nil {
+        return ""
+    }
+
+    e.lock.RLock()
+    defer e.lock.RUnlock()
+
+    return e.kind
+}
+
+// indexLabel adds a node to the label index of the graph.
+func (g *Graph) indexLabel(n *Node, label string) {
+    if g.labels == nil {
+        g.labels = make(map[string][]*Node)
+    }
+    g.labels[label] = append(g.labels[label], n)
+}
+
+// unindexLabel removes a node from the label index of the graph.
+func (g *Graph) unindexLabel(n *Node, label string) {
+
+    list := g.labels[label]
+    for i, node := range list {
+        if node == n {
+            list = append(list[:i], list[i+1:]...)
+            break
+        }
+    }
+
+    if len(list) == 0 {
+        delete(g.labels, label)
+    } else {
+        g.labels[label] = list
+    }
+}
+
+// NodesByLabel returns the nodes of the graph with the label, in the
+// order they were labelled.
+func (g *Graph) NodesByLabel(label string) []*Node {
+
+    if g == nil {
+        return make([]*Node, 0)
+    }
+
+    return append([]*Node{}, g.labels[label]...)
+}
+
+// containsString returns true if list holds s.
+func containsString(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
+
+// allowsNode returns true if the filter lets a traversal enter n.
+func (f TraversalFilter) allowsNode(n *Node) bool {
+
+    if len(f.NodeLabels) == 0 {
+        return true
+    }
+    for _, label := range f.NodeLabels {
+        if n.HasLabel(label) {
+            return true
+        }
+    }
+    return false
+}
+
+// steps returns the edges the filter follows from n, listing self loops
+// once.
+func (f TraversalFilter) steps(n *Node) []*Edge {
+
+    var edges []*Edge
+    if len(f.EdgeTypes) == 0 {
+        n.lock.RLock()
+        edges = append(edges, n.Edges...)
+        n.lock.RUnlock()
+    } else {
+        edges = n.EdgesOfType(f.EdgeTypes...)
+    }
+
+    out := make([]*Edge, 0, len(edges))
+    for i, edge := range edges {
+        // a self loop is listed twice on its node
+        if edge.ParentNode == n && edge.ChildNode == n && containsEdge(edges[:i], edge) {
+            continue
+        }
+        if (f.Direction != Incoming && edge.ParentNode == n) ||
+            (f.Direction != Outgoing && edge.ChildNode == n) {
+            out = append(out, edge)
+        }
+    }
+
+    return out
+}
+
+// containsEdge returns true if list holds e.
+func containsEdge(list []*Edge, e *Edge) bool {
+    for _, edge := range list {
+        if edge == e {
+            return true
+        }
+    }
+    return false
+}
+
+// Traverse visits the nodes reachable from start breadth first through
+// the edges and nodes the filter allows, calling visit with every node
+// and its depth in edges from start. The start node is always visited.
+// The traversal stops early when visit returns false.
+func (g *Graph) Traverse(start *Node, filter TraversalFilter, visit func(*Node, int) bool) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+    if start == nil || visit == nil {
+        return errors.New("A traversal requires a start node and a visit function")
+    }
+
+    idx := g.nodeIndexes()
+    if _, ok := idx[start]; !ok {
+        return errors.New("Start node is not part of the graph")
+    }
+
+    seen := map[*Node]bool{start: true}
+    level := []*Node{start}
+    for depth := 0; len(level) > 0; depth++ {
+        next := make([]*Node, 0)
+        for _, n := range level {
+            if !visit(n, depth) {
+                return nil
+            }
+            if filter.MaxDepth > 0 && depth >= filter.MaxDepth {
+                continue
+            }
+            for _, edge := range filter.steps(n) {
+                m := otherEnd(edge, n)
+                if _, ok := idx[m]; !ok || seen[m] || !filter.allowsNode(m) {
+                    continue
+                }
+                seen[m] = true
+                next = append(next, m)
+            }
+        }
+        level = next
+    }
+
+    return nil
+}
+
+// ShortestTrail returns the shortest walk by distance from start to end
+// through the edges and nodes the filter allows. MaxDepth is ignored.
+func (g *Graph) ShortestTrail(start *Node, end *Node, filter TraversalFilter) (Trail, error) {
+
+    if g == nil {
+        return Trail{}, errors.New("Graph is empty or nil")
+    }
+
+    idx := g.nodeIndexes()
+    src, ok1 := idx[start]
+    dst, ok2 := idx[end]
+    if !ok1 || !ok2 {
+        return Trail{}, errors.New("Start and end must be nodes of the graph")
+    }
+
+    adj := make([][]incidence, len(g.nodes))
+    for u, n := range g.nodes {
+        for _, edge := range filter.steps(n) {
+            m := otherEnd(edge, n)
+            if v, ok := idx[m]; ok && filter.allowsNode(m) {
+                adj[u] = append(adj[u], incidence{v, edge})
+            }
+        }
+    }
+
+    dist, via := shortestPaths(adj, src)
+    if math.IsInf(dist[dst], 1) {
+        return Trail{}, errors.New("No path between start and end")
+    }
+
+    // every arc records the node it was reached from
+    arcs := walkTo(via, dst)
+    trail := Trail{Nodes: []*Node{start}, Edges: make([]*Edge, 0, len(arcs))}
+    for i, arc := range arcs {
+        next := dst
+        if i+1 < len(arcs) {
+            next = arcs[i+1].to
+        }
+        trail.Nodes = append(trail.Nodes, g.nodes[next])
+        trail.Edges = append(trail.Edges, arc.edge)
+        trail.Distance += arc.edge.Distance
+    }
+
+    return trail, nil
+}
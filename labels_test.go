@@ -0,0 +1,100 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestLabelsRoundTrip(t *testing.T) {
+
+    g := NewGraph("people")
+    a, _ := g.AddNode("a", "A", "Person", "Admin")
+    b, _ := g.AddNode("b", "B", "Person")
+    c, _ := g.AddNode("c", "C")
+    if err := g.AddEdge("e1", "KNOWS", 1, a, b); err != nil {
+        t.Fatal(err)
+    }
+    if err := g.AddEdge("e2", "LIKES", 1, b, c); err != nil {
+        t.Fatal(err)
+    }
+
+    formats := []struct {
+        name   string
+        write  func(*bytes.Buffer) error
+        read   func(*bytes.Buffer) (*Graph, error)
+    }{
+        {"GraphML",
+            func(w *bytes.Buffer) error { return g.WriteGraphML(w) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadGraphML(r) }},
+        {"GEXF",
+            func(w *bytes.Buffer) error { return g.WriteGEXF(w) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadGEXF(r) }},
+        {"GML",
+            func(w *bytes.Buffer) error { return g.WriteGML(w) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadGML(r) }},
+        {"DOT",
+            func(w *bytes.Buffer) error { return g.WriteDOT(w, DOTOptions{}) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadDOT(r) }},
+        {"Pajek",
+            func(w *bytes.Buffer) error { return g.WritePajek(w) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadPajek(r) }},
+        {"CSV",
+            func(w *bytes.Buffer) error {
+                var edges bytes.Buffer
+                if err := g.WriteCSV(w, &edges, CSVOptions{}); err != nil {
+                    return err
+                }
+                w.WriteString("\x00")
+                _, err := w.Write(edges.Bytes())
+                return err
+            },
+            func(r *bytes.Buffer) (*Graph, error) {
+                tables := bytes.SplitN(r.Bytes(), []byte{0}, 2)
+                return ReadCSV(bytes.NewReader(tables[0]), bytes.NewReader(tables[1]), CSVOptions{})
+            }},
+        {"N-Triples",
+            func(w *bytes.Buffer) error { return g.WriteNTriples(w, RDFOptions{}) },
+            func(r *bytes.Buffer) (*Graph, error) { return ReadNTriples(r, RDFOptions{}) }},
+    }
+
+    for _, f := range formats {
+        var buf bytes.Buffer
+        if err := f.write(&buf); err != nil {
+            t.Fatalf("%s: write: %v", f.name, err)
+        }
+        h, err := f.read(&buf)
+        if err != nil {
+            t.Fatalf("%s: read: %v", f.name, err)
+        }
+
+        types := make(map[string]string)
+        for _, n := range h.nodes {
+            for _, e := range n.EdgesOfType("KNOWS", "LIKES") {
+                types[e.ParentNode.GetProperty("name")+e.ChildNode.GetProperty("name")] = e.Type()
+            }
+        }
+        if types["AB"] != "KNOWS" || types["BC"] != "LIKES" || len(types) != 2 {
+            t.Errorf("%s: edge types %v, want AB KNOWS and BC LIKES", f.name, types)
+        }
+
+        labels := make(map[string]string)
+        for _, n := range h.nodes {
+            labels[n.GetProperty("name")] = strings.Join(n.Labels(), ":")
+            if n.HasProperty("labels") {
+                t.Errorf("%s: node %s kept the labels property", f.name, n.GetProperty("name"))
+            }
+        }
+        if labels["A"] != "Admin:Person" || labels["B"] != "Person" || labels["C"] != "" {
+            t.Errorf("%s: labels %v, want A Admin:Person and B Person", f.name, labels)
+        }
+        if people := h.NodesByLabel("Person"); len(people) != 2 {
+            t.Errorf("%s: %d nodes indexed as Person, want 2", f.name, len(people))
+        }
+    }
+}
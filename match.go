@@ -0,0 +1,410 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+)
+
+// MatchOptions controls how a pattern graph is matched against a graph.
+type MatchOptions struct {
+    // Limit stops the search after this many matches, 0 finds all.
+    Limit      int
+    // Induced also requires every edge between matched nodes of the
+    // graph to appear in the pattern.
+    Induced    bool
+    // NodeKeys lists the node properties that must be equal. When nil
+    // every property of the pattern node except "id" and "name" is
+    // compared.
+    NodeKeys   []string
+    // EdgeKeys lists the edge properties that must be equal. When nil
+    // every property of the pattern edge except "id" is compared.
+    EdgeKeys   []string
+    // NodeMatch and EdgeMatch, if set, replace the property comparison.
+    NodeMatch  func(pattern *Node, node *Node) bool
+    EdgeMatch  func(pattern *Edge, edge *Edge) bool
+}
+
+// propertiesMatch returns true if every pattern property in keys, or all
+// pattern properties not in skip when keys is nil, is equal on the other
+// side.
+func propertiesMatch(pattern map[string]string, other map[string]string, keys []string, skip ...string) bool {
+
+    if keys != nil {
+        for _, key := range keys {
+            if pattern[key] != other[key] {
+                return false
+            }
+        }
+        return true
+    }
+
+    for key, value := range pattern {
+        skipped := false
+        for _, s := range skip {
+            if key == s {
+                skipped = true
+            }
+        }
+        if v, ok := other[key]; !skipped && (!ok || v != value) {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (o *MatchOptions) nodesMatch(p *Node, n *Node) bool {
+
+    if o.NodeMatch != nil {
+        return o.NodeMatch(p, n)
+    }
+
+    p.lock.RLock()
+    defer p.lock.RUnlock()
+    n.lock.RLock()
+    defer n.lock.RUnlock()
+
+    return propertiesMatch(p.Properties, n.Properties, o.NodeKeys, "id", "name")
+}
+
+func (o *MatchOptions) edgesMatch(p *Edge, e *Edge) bool {
+
+    if o.EdgeMatch != nil {
+        return o.EdgeMatch(p, e)
+    }
+
+    p.lock.RLock()
+    defer p.lock.RUnlock()
+    e.lock.RLock()
+    defer e.lock.RUnlock()
+
+    return propertiesMatch(p.Properties, e.Properties, o.EdgeKeys, "id")
+}
+
+// matchGraph is the view of a graph used by the matcher.
+type matchGraph struct {
+    nodes  []*Node
+    out    [][]int
+    in     [][]int
+    nbrs   [][]int
+    edges  map[[2]int][]*Edge
+}
+
+func newMatchGraph(g *Graph) *matchGraph {
+
+    idx := g.nodeIndexes()
+    n := len(g.nodes)
+    m := &matchGraph{
+        nodes:  g.nodes,
+        out:    make([][]int, n),
+        in:     make([][]int, n),
+        nbrs:   make([][]int, n),
+        edges:  make(map[[2]int][]*Edge),
+    }
+
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        key := [2]int{u, v}
+        if len(m.edges[key]) == 0 {
+            m.out[u] = append(m.out[u], v)
+            m.in[v] = append(m.in[v], u)
+        }
+        m.edges[key] = append(m.edges[key], edge)
+    }
+
+    for u := 0; u < n; u++ {
+        seen := make(map[int]bool)
+        for _, list := range [][]int{m.out[u], m.in[u]} {
+            for _, v := range list {
+                if v != u && !seen[v] {
+                    seen[v] = true
+                    m.nbrs[u] = append(m.nbrs[u], v)
+                }
+            }
+        }
+    }
+
+    return m
+}
+
+// Match finds every occurrence of the pattern graph within the graph
+// using the VF2 algorithm. Each match maps the pattern nodes to distinct
+// nodes of the graph so that every pattern edge has a matching edge in
+// the same direction.
+func (g *Graph) Match(pattern *Graph, opts MatchOptions) ([]map[*Node]*Node, error) {
+
+    out := make([]map[*Node]*Node, 0)
+
+    err := g.MatchFunc(pattern, opts, func(m map[*Node]*Node) bool {
+        out = append(out, m)
+        return true
+    })
+
+    return out, err
+}
+
+// MatchFunc calls found for every occurrence of the pattern graph within
+// the graph, see Match. The search stops early when found returns false.
+func (g *Graph) MatchFunc(pattern *Graph, opts MatchOptions, found func(map[*Node]*Node) bool) error {
+
+    if g == nil || pattern == nil {
+        return errors.New("Graph is empty or nil")
+    }
+    if found == nil {
+        return errors.New("Function required")
+    }
+    if len(pattern.nodes) == 0 || len(pattern.nodes) > len(g.nodes) {
+        return nil
+    }
+
+    s := &vf2{
+        opts:     &opts,
+        p:        newMatchGraph(pattern),
+        h:        newMatchGraph(g),
+        found:    found,
+    }
+    s.init()
+    s.search(0)
+
+    return nil
+}
+
+// vf2 holds the state of a VF2 search.
+type vf2 struct {
+    opts      *MatchOptions
+    p, h      *matchGraph
+    found     func(map[*Node]*Node) bool
+    count     int
+    stopped   bool
+
+    // order in which pattern nodes are matched, and for each the
+    // earlier pattern node and direction used to find candidates
+    order     []int
+    anchor    []int
+    anchorOut []bool
+
+    coreP     []int
+    coreH     []int
+    termP     []int
+    termH     []int
+}
+
+func (s *vf2) init() {
+
+    np := len(s.p.nodes)
+    nh := len(s.h.nodes)
+
+    s.coreP = make([]int, np)
+    s.termP = make([]int, np)
+    s.anchor = make([]int, np)
+    s.anchorOut = make([]bool, np)
+    for i := range s.coreP {
+        s.coreP[i] = -1
+        s.anchor[i] = -1
+    }
+    s.coreH = make([]int, nh)
+    s.termH = make([]int, nh)
+    for i := range s.coreH {
+        s.coreH[i] = -1
+    }
+
+    // match the most connected pattern nodes first, keeping each next
+    // node adjacent to those already ordered where possible
+    placed := make([]bool, np)
+    links := make([]int, np)
+    for len(s.order) < np {
+        best := -1
+        for u := 0; u < np; u++ {
+            if placed[u] {
+                continue
+            }
+            if best < 0 || links[u] > links[best] ||
+                (links[u] == links[best] && len(s.p.nbrs[u]) > len(s.p.nbrs[best])) {
+                best = u
+            }
+        }
+
+        placed[best] = true
+        s.order = append(s.order, best)
+        for _, v := range s.p.nbrs[best] {
+            links[v]++
+        }
+
+        for _, q := range s.p.in[best] {
+            if placed[q] && q != best {
+                s.anchor[best] = q
+                s.anchorOut[best] = true
+                break
+            }
+        }
+        if s.anchor[best] < 0 {
+            for _, q := range s.p.out[best] {
+                if placed[q] && q != best {
+                    s.anchor[best] = q
+                    break
+                }
+            }
+        }
+    }
+}
+
+func (s *vf2) search(depth int) {
+
+    if s.stopped {
+        return
+    }
+
+    if depth == len(s.order) {
+        m := make(map[*Node]*Node, len(s.order))
+        for p, h := range s.coreP {
+            m[s.p.nodes[p]] = s.h.nodes[h]
+        }
+        s.count++
+        if !s.found(m) || (s.opts.Limit > 0 && s.count >= s.opts.Limit) {
+            s.stopped = true
+        }
+        return
+    }
+
+    p := s.order[depth]
+
+    var candidates []int
+    if q := s.anchor[p]; q >= 0 {
+        if s.anchorOut[p] {
+            candidates = s.h.out[s.coreP[q]]
+        } else {
+            candidates = s.h.in[s.coreP[q]]
+        }
+    } else {
+        candidates = make([]int, len(s.h.nodes))
+        for i := range candidates {
+            candidates[i] = i
+        }
+    }
+
+    for _, h := range candidates {
+        if s.coreH[h] >= 0 || !s.feasible(p, h) {
+            continue
+        }
+
+        s.assign(p, h, 1)
+        s.search(depth + 1)
+        s.assign(p, h, -1)
+
+        if s.stopped {
+            return
+        }
+    }
+}
+
+// assign adds (delta 1) or removes (delta -1) the pair p, h from the
+// mapping and updates the terminal counters of their neighbours.
+func (s *vf2) assign(p int, h int, delta int) {
+
+    if delta > 0 {
+        s.coreP[p] = h
+        s.coreH[h] = p
+    } else {
+        s.coreP[p] = -1
+        s.coreH[h] = -1
+    }
+
+    for _, v := range s.p.nbrs[p] {
+        s.termP[v] += delta
+    }
+    for _, v := range s.h.nbrs[h] {
+        s.termH[v] += delta
+    }
+}
+
+// linked returns true if every pattern edge from p to q has a matching
+// edge from h to k.
+func (s *vf2) linked(p, q, h, k int) bool {
+
+    for _, pe := range s.p.edges[[2]int{p, q}] {
+        ok := false
+        for _, he := range s.h.edges[[2]int{h, k}] {
+            if s.opts.edgesMatch(pe, he) {
+                ok = true
+                break
+            }
+        }
+        if !ok {
+            return false
+        }
+    }
+
+    return true
+}
+
+func (s *vf2) feasible(p int, h int) bool {
+
+    if len(s.h.out[h]) < len(s.p.out[p]) || len(s.h.in[h]) < len(s.p.in[p]) {
+        return false
+    }
+
+    if !s.opts.nodesMatch(s.p.nodes[p], s.h.nodes[h]) {
+        return false
+    }
+
+    if !s.linked(p, p, h, h) {
+        return false
+    }
+    if s.opts.Induced && len(s.h.edges[[2]int{h, h}]) > 0 && len(s.p.edges[[2]int{p, p}]) == 0 {
+        return false
+    }
+
+    for _, q := range s.p.out[p] {
+        if k := s.coreP[q]; k >= 0 && !s.linked(p, q, h, k) {
+            return false
+        }
+    }
+    for _, q := range s.p.in[p] {
+        if k := s.coreP[q]; k >= 0 && !s.linked(q, p, k, h) {
+            return false
+        }
+    }
+
+    if s.opts.Induced {
+        for _, k := range s.h.out[h] {
+            if q := s.coreH[k]; q >= 0 && len(s.p.edges[[2]int{p, q}]) == 0 {
+                return false
+            }
+        }
+        for _, k := range s.h.in[h] {
+            if q := s.coreH[k]; q >= 0 && len(s.p.edges[[2]int{q, p}]) == 0 {
+                return false
+            }
+        }
+    }
+
+    // look ahead: unmatched pattern neighbours, both next to the current
+    // mapping and beyond it, need room among the neighbours of h
+    termP, restP := 0, 0
+    for _, v := range s.p.nbrs[p] {
+        if s.coreP[v] < 0 {
+            if s.termP[v] > 0 {
+                termP++
+            } else {
+                restP++
+            }
+        }
+    }
+    termH, restH := 0, 0
+    for _, v := range s.h.nbrs[h] {
+        if s.coreH[v] < 0 {
+            if s.termH[v] > 0 {
+                termH++
+            } else {
+                restH++
+            }
+        }
+    }
+
+    return termP <= termH && termP+restP <= termH+restH
+}
@@ -0,0 +1,78 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "testing"
+)
+
+func TestMatch(t *testing.T) {
+
+    // the cycle a b c with the chord a c
+    g := edgeGraph(t, "a b", "b c", "c a", "a c")
+    g.nodes[1].AddProperty("color", "red")
+
+    // K4 with edges both ways
+    complete := edgeGraph(t, "1 2", "2 1", "1 3", "3 1", "1 4", "4 1", "2 3", "3 2", "2 4", "4 2", "3 4", "4 3")
+
+    red := edgeGraph(t, "x y")
+    red.nodes[1].AddProperty("color", "red")
+
+    tests := []struct {
+        name     string
+        g        *Graph
+        pattern  *Graph
+        opts     MatchOptions
+        want     int
+    }{
+        {"edge", g, edgeGraph(t, "x y"), MatchOptions{}, 4},
+        {"cycle", g, edgeGraph(t, "x y", "y z", "z x"), MatchOptions{}, 3},
+        {"two step path", g, edgeGraph(t, "x y", "y z"), MatchOptions{}, 3},
+        {"induced two step path", g, edgeGraph(t, "x y", "y z"), MatchOptions{Induced: true}, 0},
+        {"reverse edges", g, edgeGraph(t, "x y", "y x"), MatchOptions{}, 2},
+        {"red end", g, red, MatchOptions{}, 1},
+        {"red end by key", g, red, MatchOptions{NodeKeys: []string{"color"}}, 1},
+        {"red end ignored", g, red, MatchOptions{NodeKeys: []string{}}, 4},
+        {"triangles of K4", complete, edgeGraph(t, "x y", "y x", "y z", "z y", "z x", "x z"), MatchOptions{}, 24},
+        {"limit", complete, edgeGraph(t, "x y"), MatchOptions{Limit: 5}, 5},
+        {"too large", g, edgeGraph(t, "w x", "x y", "y z"), MatchOptions{}, 0},
+    }
+
+    for _, test := range tests {
+        matches, err := test.g.Match(test.pattern, test.opts)
+        if err != nil {
+            t.Errorf("%s: %v", test.name, err)
+            continue
+        }
+        if len(matches) != test.want {
+            t.Errorf("%s: %d matches, want %d", test.name, len(matches), test.want)
+        }
+        for _, m := range matches {
+            used := make(map[*Node]bool)
+            for p, n := range m {
+                if used[n] {
+                    t.Errorf("%s: two pattern nodes map to %s", test.name, n.GetProperty("id"))
+                }
+                used[n] = true
+                for _, e := range p.Edges {
+                    if e.ParentNode == p && !linked(n, m[e.ChildNode]) {
+                        t.Errorf("%s: pattern edge not matched", test.name)
+                    }
+                }
+            }
+        }
+    }
+}
+
+// linked reports whether an edge leads from parent to child.
+func linked(parent *Node, child *Node) bool {
+    for _, e := range parent.Edges {
+        if e.ParentNode == parent && e.ChildNode == child {
+            return true
+        }
+    }
+    return false
+}
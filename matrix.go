@@ -0,0 +1,361 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// COOMatrix is a sparse matrix in coordinate form, with entries sorted
+// by row and then column.
+type COOMatrix struct {
+    Size    int
+    Rows    []int
+    Cols    []int
+    Values  []float64
+}
+
+// CSRMatrix is a sparse matrix in compressed sparse row form. The
+// entries of row i are at positions RowStart[i] to RowStart[i+1] of
+// Cols and Values, sorted by column.
+type CSRMatrix struct {
+    Size      int
+    RowStart  []int
+    Cols      []int
+    Values    []float64
+}
+
+// At returns the entry at row i and column j and whether it is set.
+func (m CSRMatrix) At(i int, j int) (float64, bool) {
+
+    if i < 0 || i >= m.Size {
+        return 0, false
+    }
+
+    cols := m.Cols[m.RowStart[i]:m.RowStart[i+1]]
+    k := sort.SearchInts(cols, j)
+    if k < len(cols) && cols[k] == j {
+        return m.Values[m.RowStart[i]+k], true
+    }
+    return 0, false
+}
+
+// coo builds the adjacency matrix with rows and columns in node order.
+// Parallel edges keep the smallest distance. Edges with the property
+// "directed" set to "false" are entered in both directions, as is every
+// edge when directed is false.
+func (g *Graph) coo(directed bool) COOMatrix {
+
+    entries := make(map[[2]int]float64)
+    set := func(i int, j int, d float64) {
+        if old, ok := entries[[2]int{i, j}]; !ok || d < old {
+            entries[[2]int{i, j}] = d
+        }
+    }
+
+    idx := g.nodeIndexes()
+    for _, edge := range g.edges() {
+        u := idx[edge.ParentNode]
+        v := idx[edge.ChildNode]
+        set(u, v, edge.Distance)
+        if !directed || edge.GetProperty("directed") == "false" {
+            set(v, u, edge.Distance)
+        }
+    }
+
+    keys := make([][2]int, 0, len(entries))
+    for key := range entries {
+        keys = append(keys, key)
+    }
+    sort.Slice(keys, func(a, b int) bool {
+        if keys[a][0] != keys[b][0] {
+            return keys[a][0] < keys[b][0]
+        }
+        return keys[a][1] < keys[b][1]
+    })
+
+    m := COOMatrix{
+        Size:    len(g.nodes),
+        Rows:    make([]int, len(keys)),
+        Cols:    make([]int, len(keys)),
+        Values:  make([]float64, len(keys)),
+    }
+    for k, key := range keys {
+        m.Rows[k] = key[0]
+        m.Cols[k] = key[1]
+        m.Values[k] = entries[key]
+    }
+
+    return m
+}
+
+// rowNodes returns the node of every matrix row.
+func (g *Graph) rowNodes() []*Node {
+    return append([]*Node{}, g.nodes...)
+}
+
+// AdjacencyMatrix returns the dense adjacency matrix of the graph with
+// edge distances as values, and the node of every row and column. Cells
+// without an edge are 0. Parallel edges keep the smallest distance, edges
+// with the property "directed" set to "false" fill both cells, and when
+// directed is false the matrix is symmetric.
+func (g *Graph) AdjacencyMatrix(directed bool) ([][]float64, []*Node, error) {
+
+    if g == nil {
+        return nil, nil, errors.New("Graph is empty or nil")
+    }
+
+    coo := g.coo(directed)
+    m := make([][]float64, coo.Size)
+    for i := range m {
+        m[i] = make([]float64, coo.Size)
+    }
+    for k := range coo.Values {
+        m[coo.Rows[k]][coo.Cols[k]] = coo.Values[k]
+    }
+
+    return m, g.rowNodes(), nil
+}
+
+// COO returns the adjacency matrix as AdjacencyMatrix does, in
+// coordinate form.
+func (g *Graph) COO(directed bool) (COOMatrix, []*Node, error) {
+
+    if g == nil {
+        return COOMatrix{}, nil, errors.New("Graph is empty or nil")
+    }
+
+    return g.coo(directed), g.rowNodes(), nil
+}
+
+// CSR returns the adjacency matrix as AdjacencyMatrix does, in
+// compressed sparse row form.
+func (g *Graph) CSR(directed bool) (CSRMatrix, []*Node, error) {
+
+    if g == nil {
+        return CSRMatrix{}, nil, errors.New("Graph is empty or nil")
+    }
+
+    coo := g.coo(directed)
+    m := CSRMatrix{
+        Size:      coo.Size,
+        RowStart:  make([]int, coo.Size+1),
+        Cols:      coo.Cols,
+        Values:    coo.Values,
+    }
+    for _, row := range coo.Rows {
+        m.RowStart[row+1]++
+    }
+    for i := 0; i < coo.Size; i++ {
+        m.RowStart[i+1] += m.RowStart[i]
+    }
+
+    return m, g.rowNodes(), nil
+}
+
+// WriteMatrixMarket writes the adjacency matrix as AdjacencyMatrix
+// builds it to a Matrix Market coordinate file, and returns the node of
+// every row and column. When directed is false the matrix is written as
+// symmetric, listing only entries on or below the diagonal.
+func (g *Graph) WriteMatrixMarket(w io.Writer, directed bool) ([]*Node, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    coo := g.coo(directed)
+    symmetry := "general"
+    entries := make([]int, 0, len(coo.Values))
+    for k := range coo.Values {
+        if directed || coo.Rows[k] >= coo.Cols[k] {
+            entries = append(entries, k)
+        }
+    }
+    if !directed {
+        symmetry = "symmetric"
+    }
+
+    bw := bufio.NewWriter(w)
+    bw.WriteString("%%MatrixMarket matrix coordinate real " + symmetry + "\n")
+    size := strconv.Itoa(coo.Size)
+    bw.WriteString(size + " " + size + " " + strconv.Itoa(len(entries)) + "\n")
+    for _, k := range entries {
+        bw.WriteString(strconv.Itoa(coo.Rows[k]+1) + " " + strconv.Itoa(coo.Cols[k]+1) + " " +
+            strconv.FormatFloat(coo.Values[k], 'g', -1, 64) + "\n")
+    }
+
+    if err := bw.Flush(); err != nil {
+        return nil, err
+    }
+    return g.rowNodes(), nil
+}
+
+// ReadMatrixMarket reads a square Matrix Market matrix in coordinate or
+// array format with real, integer or pattern values. Node i has the id
+// and name i+1, its row and column number in the file. Every entry
+// becomes an edge from its row to its column with the value as distance,
+// 1 for pattern matrices; zeros of array matrices are left out. Entries
+// of symmetric matrices become single edges with the property "directed"
+// set to "false" off the diagonal. Distances can not be negative, so
+// negative values and skew-symmetric matrices are rejected.
+func ReadMatrixMarket(r io.Reader) (*Graph, error) {
+
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+    if !scanner.Scan() {
+        return nil, errors.New("Matrix Market file is empty")
+    }
+    header := strings.Fields(strings.ToLower(scanner.Text()))
+    if len(header) != 5 || header[0] != "%%matrixmarket" || header[1] != "matrix" {
+        return nil, errors.New("Not a Matrix Market matrix")
+    }
+    format, field, symmetry := header[2], header[3], header[4]
+    if format != "coordinate" && format != "array" {
+        return nil, errors.New("Unsupported Matrix Market format " + format)
+    }
+    if field != "real" && field != "integer" && field != "double" && field != "pattern" {
+        return nil, errors.New("Unsupported Matrix Market field " + field)
+    }
+    if symmetry != "general" && symmetry != "symmetric" {
+        return nil, errors.New("Unsupported Matrix Market symmetry " + symmetry)
+    }
+    if format == "array" && field == "pattern" {
+        return nil, errors.New("Array matrices cannot be patterns")
+    }
+
+    // lines returns the fields of the next line that is not a comment
+    line := 1
+    lines := func() ([]string, bool) {
+        for scanner.Scan() {
+            line++
+            text := strings.TrimSpace(scanner.Text())
+            if len(text) > 0 && text[0] != '%' {
+                return strings.Fields(text), true
+            }
+        }
+        return nil, false
+    }
+    fail := func(msg string) (*Graph, error) {
+        return nil, errors.New("Matrix Market line " + strconv.Itoa(line) + ": " + msg)
+    }
+
+    size, ok := lines()
+    if !ok {
+        return fail("Missing size")
+    }
+    if (format == "coordinate" && len(size) != 3) || (format == "array" && len(size) != 2) {
+        return fail("Invalid size")
+    }
+    dims := make([]int, len(size))
+    for i, s := range size {
+        v, err := strconv.Atoi(s)
+        if err != nil || v < 0 {
+            return fail("Invalid size")
+        }
+        dims[i] = v
+    }
+    n := dims[0]
+    if dims[1] != n {
+        return fail("Matrix is not square")
+    }
+
+    g := NewGraph("")
+    nodes := make([]*Node, n)
+    for i := range nodes {
+        nodes[i] = NewNode()
+        nodes[i].Properties["id"] = strconv.Itoa(i + 1)
+        nodes[i].Properties["name"] = nodes[i].Properties["id"]
+        g.insertNode(nodes[i])
+    }
+
+    link := func(i int, j int, d float64) error {
+        e := NewEdge()
+        if symmetry == "symmetric" && i != j {
+            e.Properties["directed"] = "false"
+        }
+        e.SetDistance(d)
+        return e.Link(nodes[i], nodes[j])
+    }
+
+    // distances must be numbers of at least 0
+    value := func(s string) (float64, bool) {
+        v, err := strconv.ParseFloat(s, 64)
+        return v, err == nil && v >= 0
+    }
+
+    if format == "coordinate" {
+        for k := 0; k < dims[2]; k++ {
+            fields, ok := lines()
+            if !ok {
+                return fail("Missing entries")
+            }
+            want := 3
+            if field == "pattern" {
+                want = 2
+            }
+            if len(fields) != want {
+                return fail("Invalid entry")
+            }
+            i, err1 := strconv.Atoi(fields[0])
+            j, err2 := strconv.Atoi(fields[1])
+            if err1 != nil || err2 != nil || i < 1 || i > n || j < 1 || j > n {
+                return fail("Invalid entry")
+            }
+            if symmetry == "symmetric" && j > i {
+                return fail("Entry above the diagonal of a symmetric matrix")
+            }
+            d := 1.0
+            if field != "pattern" {
+                if d, ok = value(fields[2]); !ok {
+                    return fail("Invalid or negative value")
+                }
+            }
+            if err := link(i-1, j-1, d); err != nil {
+                return nil, err
+            }
+        }
+    } else {
+        // array entries run down each column, from the diagonal for
+        // symmetric matrices
+        for j := 0; j < n; j++ {
+            start := 0
+            if symmetry == "symmetric" {
+                start = j
+            }
+            for i := start; i < n; i++ {
+                fields, ok := lines()
+                if !ok {
+                    return fail("Missing entries")
+                }
+                d, ok := value(fields[0])
+                if len(fields) != 1 || !ok {
+                    return fail("Invalid or negative value")
+                }
+                if d == 0 {
+                    continue
+                }
+                if err := link(i, j, d); err != nil {
+                    return nil, err
+                }
+            }
+        }
+    }
+
+    if fields, ok := lines(); ok {
+        return fail("Unexpected data " + strings.Join(fields, " "))
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    return g, nil
+}
@@ -0,0 +1,160 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "fmt"
+    "strings"
+    "testing"
+)
+
+func TestReadMatrixMarket(t *testing.T) {
+
+    tests := []struct {
+        file   string
+        want   string
+    }{
+        {"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2 4\n", "[[0 4] [0 0]]"},
+        {"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n2 1 5\n", "[[0 5] [5 0]]"},
+        {"%%MatrixMarket matrix coordinate pattern general\n2 2 2\n1 1\n2 1\n", "[[1 0] [1 0]]"},
+        {"%%MatrixMarket matrix array real symmetric\n2 2\n1\n3\n0\n", "[[1 3] [3 0]]"},
+        {"%%MatrixMarket matrix array integer general\n2 2\n0\n2\n7\n0\n", "[[0 7] [2 0]]"},
+    }
+
+    for _, test := range tests {
+        g, err := ReadMatrixMarket(strings.NewReader(test.file))
+        if err != nil {
+            t.Errorf("%q: %v", test.file, err)
+            continue
+        }
+        m, _, _ := g.AdjacencyMatrix(true)
+        if got := fmt.Sprint(m); got != test.want {
+            t.Errorf("%q: got %s, want %s", test.file, got, test.want)
+        }
+    }
+}
+
+func TestReadMatrixMarketErrors(t *testing.T) {
+
+    files := []string{
+        "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2 -4\n",
+        "%%MatrixMarket matrix coordinate real skew-symmetric\n2 2 1\n2 1 -3\n",
+        "%%MatrixMarket matrix array real general\n2 2\n1\nNaN\n0\n0\n",
+        "%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 5\n",
+        "%%MatrixMarket matrix coordinate real general\n2 3 0\n",
+        "%%MatrixMarket matrix coordinate real general\n2 2 2\n1 2 4\n",
+        "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 3 4\n",
+    }
+
+    for _, file := range files {
+        if _, err := ReadMatrixMarket(strings.NewReader(file)); err == nil {
+            t.Errorf("%q: read without error", file)
+        }
+    }
+}
+
+func TestMatrixMarketRoundTrip(t *testing.T) {
+
+    g := NewGraph("")
+    a, _ := g.AddNode("a", "A")
+    b, _ := g.AddNode("b", "B")
+    c, _ := g.AddNode("c", "C")
+    g.AddEdge("ab", "to", 0.5, a, b)
+    g.AddEdge("bc", "to", 0.25, b, c)
+    g.AddEdge("cc", "to", 1, c, c)
+
+    for _, directed := range []bool{true, false} {
+        want, _, _ := g.AdjacencyMatrix(directed)
+
+        var buf bytes.Buffer
+        if _, err := g.WriteMatrixMarket(&buf, directed); err != nil {
+            t.Fatal(err)
+        }
+        h, err := ReadMatrixMarket(&buf)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got, _, _ := h.AdjacencyMatrix(true)
+        if fmt.Sprint(got) != fmt.Sprint(want) {
+            t.Errorf("directed %v: got %v, want %v", directed, got, want)
+        }
+    }
+}
+
+func TestAdjacencyMatrix(t *testing.T) {
+
+    // parallel edges a b keep the smaller distance, b c counts both ways
+    g := edgeGraph(t, "a b 3", "a b 2", "b c 4", "c c 1")
+    for _, e := range g.edges() {
+        if e.GetProperty("id") == "e2" {
+            e.AddProperty("directed", "false")
+        }
+    }
+
+    tests := []struct {
+        directed  bool
+        dense     string
+        coo       string
+        file      string
+    }{
+        {true, "[[0 2 0] [0 0 4] [0 4 1]]", "[0 1 2 2] [1 2 1 2] [2 4 4 1]",
+            "%%MatrixMarket matrix coordinate real general\n3 3 4\n1 2 2\n2 3 4\n3 2 4\n3 3 1\n"},
+        {false, "[[0 2 0] [2 0 4] [0 4 1]]", "[0 1 1 2 2] [1 0 2 1 2] [2 2 4 4 1]",
+            "%%MatrixMarket matrix coordinate real symmetric\n3 3 3\n2 1 2\n3 2 4\n3 3 1\n"},
+    }
+
+    for _, test := range tests {
+        m, nodes, err := g.AdjacencyMatrix(test.directed)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := fmt.Sprint(m); got != test.dense || nodeNames(nodes) != "a b c" {
+            t.Errorf("directed %v: got %s for %s, want %s", test.directed, got, nodeNames(nodes), test.dense)
+        }
+
+        coo, _, err := g.COO(test.directed)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := fmt.Sprint(coo.Rows, coo.Cols, coo.Values); got != test.coo || coo.Size != 3 {
+            t.Errorf("directed %v: COO %s, want %s", test.directed, got, test.coo)
+        }
+
+        var buf bytes.Buffer
+        if _, err := g.WriteMatrixMarket(&buf, test.directed); err != nil {
+            t.Fatal(err)
+        }
+        if got := buf.String(); got != test.file {
+            t.Errorf("directed %v: file\n%s\nwant\n%s", test.directed, got, test.file)
+        }
+    }
+
+    var empty *Graph
+    if _, _, err := empty.AdjacencyMatrix(true); err == nil {
+        t.Errorf("adjacency matrix of a nil graph did not fail")
+    }
+}
+
+func TestCSR(t *testing.T) {
+
+    g, _ := ReadMatrixMarket(strings.NewReader(
+        "%%MatrixMarket matrix coordinate real general\n3 3 3\n1 2 4\n3 1 2\n1 3 1\n"))
+    m, _, err := g.CSR(true)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if fmt.Sprint(m.RowStart, m.Cols, m.Values) != "[0 2 2 3] [1 2 0] [4 1 2]" {
+        t.Errorf("got %v %v %v", m.RowStart, m.Cols, m.Values)
+    }
+    if v, ok := m.At(2, 0); v != 2 || !ok {
+        t.Errorf("At(2, 0) = %v %v", v, ok)
+    }
+    if _, ok := m.At(1, 1); ok {
+        t.Errorf("At(1, 1) is set")
+    }
+}
@@ -14,6 +14,10 @@ import (
 type Node struct {
     Edges       []*Edge
     Properties  map[string]string
+    values      map[string]typedValue
+    labels      []string
+    edgeTypes   map[string][]*Edge
+    graph       *Graph
     lock        sync.RWMutex
     index       int
 	state       int
@@ -122,30 +126,46 @@ func (n *Node) ChildEdges() []*Edge {
     return out
 }
 
-// AddProperty adds a property to the node given.
-func (n *Node) AddProperty(key string, value string) {
+// AddProperty adds a property to the node given. It fails if
+// the graph schema does not allow the value.
+func (n *Node) AddProperty(key string, value string) error {
     
     if n == nil {
-        return
+        return errors.New("Node is nil")
+    }
+    
+    if err := n.checkProperty(key, value, true); err != nil {
+        return err
     }
     
     n.lock.Lock()
     defer n.lock.Unlock()
     
     n.Properties[key] = value
+    delete(n.values, key)
+    
+    return nil
 }
 
-// RemProperty removes a property from the node given.
-func (n *Node) RemProperty(key string) {
+// RemProperty removes a property from the node given. It fails
+// if the graph schema requires the property.
+func (n *Node) RemProperty(key string) error {
     
     if n == nil {
-        return
+        return errors.New("Node is nil")
+    }
+    
+    if err := n.checkProperty(key, "", false); err != nil {
+        return err
     }
     
     n.lock.Lock()
     defer n.lock.Unlock()
     
     delete(n.Properties, key)
+    delete(n.values, key)
+    
+    return nil
 }
 
 // GetProperty returns a property from the given node.
@@ -178,6 +198,20 @@ func (n *Node) HasProperty(key string) bool {
     return false
 }
 
+// copyProperties returns a copy of the node properties.
+func (n *Node) copyProperties() map[string]string {
+    
+    n.lock.RLock()
+    defer n.lock.RUnlock()
+    
+    out := make(map[string]string, len(n.Properties))
+    for key, value := range n.Properties {
+        out[key] = value
+    }
+    
+    return out
+}
+
 // NumLinks returns the number of parent and child links
 // from the given node.
 func (n *Node) NumLinks() (parent int, child int) {
@@ -207,6 +241,7 @@ func (n *Node) addEdge(e *Edge) error {
     defer n.lock.Unlock()
     
     n.Edges = append(n.Edges, e)
+    n.indexEdge(e, e.kind)
     
     return nil
 }
@@ -235,6 +270,7 @@ func (n *Node) removeEdge(e *Edge) error {
         return nil
     }
     
+    n.unindexEdge(e, e.kind)
     copy(n.Edges[eI:], n.Edges[eI+1:])
     n.Edges[len(n.Edges)-1] = nil
     n.Edges = n.Edges[:len(n.Edges)-1]
@@ -0,0 +1,290 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// pajekQuote quotes a label. Pajek has no escapes, so double quotes
+// inside the label are replaced by single ones.
+func pajekQuote(s string) string {
+    return "\"" + strings.Replace(s, "\"", "'", -1) + "\""
+}
+
+// WritePajek writes the graph as a Pajek .net file. Vertices are
+// numbered by position and labelled with the "name" property, followed
+// by their "x", "y" and "z" properties as coordinates when "x" and "y"
+// are present, and node labels as the "labels" parameter, ":Person".
+// Edges with the property "directed" set to "false" are listed under
+// *Edges, all others under *Arcs, with their distance as the line value
+// and their "name" as the line label. Other properties are not written.
+func (g *Graph) WritePajek(w io.Writer) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    idx := g.nodeIndexes()
+    bw := bufio.NewWriter(w)
+
+    if len(g.id) > 0 {
+        bw.WriteString("*Network " + g.id + "\n")
+    }
+    bw.WriteString("*Vertices " + strconv.Itoa(len(g.nodes)) + "\n")
+    for i, node := range g.nodes {
+        p := node.fileProperties()
+        name, ok := p["name"]
+        if !ok {
+            name = strconv.Itoa(i + 1)
+        }
+        bw.WriteString(strconv.Itoa(i+1) + " " + pajekQuote(name))
+        if _, ok := p["x"]; ok {
+            if _, ok := p["y"]; ok {
+                for _, key := range []string{"x", "y", "z"} {
+                    if value, ok := p[key]; ok {
+                        bw.WriteString(" " + value)
+                    }
+                }
+            }
+        }
+        if labels, ok := p["labels"]; ok {
+            bw.WriteString(" labels " + pajekQuote(labels))
+        }
+        bw.WriteString("\n")
+    }
+
+    arcs := make([]*Edge, 0)
+    lines := make([]*Edge, 0)
+    for _, edge := range g.edges() {
+        if edge.GetProperty("directed") == "false" {
+            lines = append(lines, edge)
+        } else {
+            arcs = append(arcs, edge)
+        }
+    }
+
+    sections := []struct {
+        title  string
+        edges  []*Edge
+    }{
+        {"*Arcs", arcs},
+        {"*Edges", lines},
+    }
+    for _, section := range sections {
+        if len(section.edges) == 0 {
+            continue
+        }
+        bw.WriteString(section.title + "\n")
+        for _, edge := range section.edges {
+            bw.WriteString(strconv.Itoa(idx[edge.ParentNode]+1) + " " +
+                strconv.Itoa(idx[edge.ChildNode]+1) + " " +
+                strconv.FormatFloat(edge.Distance, 'g', -1, 64))
+            if edge.HasProperty("name") {
+                bw.WriteString(" l " + pajekQuote(edge.GetProperty("name")))
+            }
+            bw.WriteString("\n")
+        }
+    }
+
+    return bw.Flush()
+}
+
+// pajekFields splits a line on white space, keeping quoted text
+// together without its quotes.
+func pajekFields(line string) ([]string, error) {
+
+    fields := make([]string, 0)
+    for {
+        line = strings.TrimLeft(line, " \t")
+        if len(line) == 0 {
+            return fields, nil
+        }
+        if line[0] == '"' {
+            end := strings.IndexByte(line[1:], '"')
+            if end < 0 {
+                return nil, errors.New("Unterminated Pajek label")
+            }
+            fields = append(fields, line[1:end+1])
+            line = line[end+2:]
+            continue
+        }
+        end := strings.IndexAny(line, " \t")
+        if end < 0 {
+            end = len(line)
+        }
+        fields = append(fields, line[:end])
+        line = line[end:]
+    }
+}
+
+// pajekParameters stores key value pairs as properties.
+func pajekParameters(props map[string]string, fields []string) {
+    for i := 0; i+1 < len(fields); i += 2 {
+        props[fields[i]] = fields[i+1]
+    }
+}
+
+// ReadPajek reads a Pajek .net file with *Vertices followed by any of
+// *Arcs, *Edges, *Arcslist and *Edgeslist sections. Vertex numbers
+// become the "id" property and labels the "name" property; coordinates
+// become the "x", "y" and "z" properties and other vertex parameters
+// properties of the same name, except that "labels" such as
+// ":Person:Place" sets the node labels. Line values set Edge.Distance,
+// defaulting to 1, and the "l" parameter becomes the "name" property.
+// Lines listed under *Edges or *Edgeslist get the property "directed"
+// set to "false".
+func ReadPajek(r io.Reader) (*Graph, error) {
+
+    g := NewGraph("")
+    nodes := make([]*Node, 0)
+    section := ""
+
+    node := func(field string) (*Node, error) {
+        i, err := strconv.Atoi(field)
+        if err != nil || i < 1 || i > len(nodes) {
+            return nil, errors.New("Unknown Pajek vertex: " + field)
+        }
+        return nodes[i-1], nil
+    }
+
+    link := func(from *Node, to *Node, distance float64, params []string) error {
+        e := NewEdge()
+        pajekParameters(e.Properties, params)
+        if label, ok := e.Properties["l"]; ok {
+            e.Properties["name"] = label
+            delete(e.Properties, "l")
+        }
+        if strings.HasPrefix(section, "*edges") {
+            e.Properties["directed"] = "false"
+        }
+        e.SetDistance(distance)
+        e.kind = e.Properties["name"]
+        return e.Link(from, to)
+    }
+
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+    line := 0
+    for scanner.Scan() {
+        line++
+        text := strings.TrimSpace(scanner.Text())
+        if len(text) == 0 || text[0] == '%' {
+            continue
+        }
+
+        fail := func(err error) (*Graph, error) {
+            return nil, errors.New("Pajek line " + strconv.Itoa(line) + ": " + err.Error())
+        }
+
+        if text[0] == '*' {
+            head := strings.Fields(text)
+            section = strings.ToLower(head[0])
+            switch section {
+            case "*network":
+                g.id = strings.TrimSpace(text[len(head[0]):])
+            case "*vertices":
+                if len(head) < 2 {
+                    return fail(errors.New("Missing vertex count"))
+                }
+                count, err := strconv.Atoi(head[1])
+                if err != nil || count < 0 {
+                    return fail(errors.New("Invalid vertex count"))
+                }
+                for i := len(nodes); i < count; i++ {
+                    n := NewNode()
+                    n.Properties["id"] = strconv.Itoa(i + 1)
+                    n.Properties["name"] = n.Properties["id"]
+                    nodes = append(nodes, n)
+                    g.insertNode(n)
+                }
+            case "*arcs", "*edges", "*arcslist", "*edgeslist":
+            default:
+                return fail(errors.New("Unsupported Pajek section " + head[0]))
+            }
+            continue
+        }
+
+        fields, err := pajekFields(text)
+        if err != nil {
+            return fail(err)
+        }
+
+        switch section {
+        case "*vertices":
+            n, err := node(fields[0])
+            if err != nil {
+                return fail(err)
+            }
+            rest := fields[1:]
+            if len(rest) > 0 {
+                n.Properties["name"] = rest[0]
+                rest = rest[1:]
+            }
+            for _, key := range []string{"x", "y", "z"} {
+                if len(rest) == 0 {
+                    break
+                }
+                if _, err := strconv.ParseFloat(rest[0], 64); err != nil {
+                    break
+                }
+                n.Properties[key] = rest[0]
+                rest = rest[1:]
+            }
+            pajekParameters(n.Properties, rest)
+            n.readLabels()
+        case "*arcs", "*edges":
+            if len(fields) < 2 {
+                return fail(errors.New("Line needs two vertices"))
+            }
+            from, err := node(fields[0])
+            if err != nil {
+                return fail(err)
+            }
+            to, err := node(fields[1])
+            if err != nil {
+                return fail(err)
+            }
+            distance := 1.0
+            rest := fields[2:]
+            if len(rest) > 0 {
+                if v, err := strconv.ParseFloat(rest[0], 64); err == nil {
+                    distance = v
+                    rest = rest[1:]
+                }
+            }
+            if err := link(from, to, distance, rest); err != nil {
+                return fail(err)
+            }
+        case "*arcslist", "*edgeslist":
+            from, err := node(fields[0])
+            if err != nil {
+                return fail(err)
+            }
+            for _, field := range fields[1:] {
+                to, err := node(field)
+                if err != nil {
+                    return fail(err)
+                }
+                if err := link(from, to, 1, nil); err != nil {
+                    return fail(err)
+                }
+            }
+        default:
+            return fail(errors.New("Data before *Vertices"))
+        }
+    }
+
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    return g, nil
+}
@@ -0,0 +1,112 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestPajekRoundTrip(t *testing.T) {
+
+    g := NewGraph("net")
+    a, _ := g.AddNode("a", `say "hi"`, "Person")
+    b, _ := g.AddNode("b", "B")
+    c := NewNode()
+    g.insertNode(c)
+    a.AddProperty("x", "0.5")
+    a.AddProperty("y", "1")
+    g.AddEdge("ab", "knows", 0.5, a, b)
+    g.AddEdge("bc", "near", 1, b, c)
+    for _, e := range g.edges() {
+        if e.GetProperty("id") == "bc" {
+            e.AddProperty("directed", "false")
+        }
+    }
+
+    var buf bytes.Buffer
+    if err := g.WritePajek(&buf); err != nil {
+        t.Fatal(err)
+    }
+    want := `*Network net
+*Vertices 3
+1 "say 'hi'" 0.5 1 labels ":Person"
+2 "B"
+3 "3"
+*Arcs
+1 2 2 l "knows"
+*Edges
+2 3 1 l "near"
+`
+    if got := buf.String(); got != want {
+        t.Errorf("Pajek\n%s\nwant\n%s", got, want)
+    }
+
+    h, err := ReadPajek(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    first, _ := h.GetNodeById("1")
+    if h.id != "net" || first == nil || first.GetProperty("name") != "say 'hi'" || first.GetProperty("x") != "0.5" ||
+        !first.HasLabel("Person") {
+        t.Fatalf("graph %q with first node %v", h.id, first)
+    }
+    if got, want := edgeList(h), "1 2 2 knows\n2 3 1 near"; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+}
+
+func TestReadPajek(t *testing.T) {
+
+    src := `% a comment
+*Vertices 4
+1 "One" 0.1 0.2 0.3 ic Red
+2 Two
+*Arcs
+1 2 3 c Blue
+2 3
+*Arcslist
+4 1 2
+*Edgeslist
+3 4
+`
+    g, err := ReadPajek(strings.NewReader(src))
+    if err != nil {
+        t.Fatal(err)
+    }
+    one, _ := g.GetNodeById("1")
+    four, _ := g.GetNodeById("4")
+    if len(g.nodes) != 4 || four == nil || four.GetProperty("name") != "4" {
+        t.Fatalf("%d nodes, want 4 with unlisted vertices named by number", len(g.nodes))
+    }
+    if one.GetProperty("name") != "One" || one.GetProperty("z") != "0.3" || one.GetProperty("ic") != "Red" {
+        t.Errorf("vertex properties %v", one.copyProperties())
+    }
+    if got, want := edgeList(g), "1 2 3 \n2 3 1 \n3 4 1 \n4 1 1 \n4 2 1 "; got != want {
+        t.Errorf("edges\n%q\nwant\n%q", got, want)
+    }
+    for _, e := range g.edges() {
+        undirected := e.GetProperty("directed") == "false"
+        if undirected != (e.ParentNode.GetProperty("id") == "3") {
+            t.Errorf("edge from %s has directed %q", e.ParentNode.GetProperty("id"), e.GetProperty("directed"))
+        }
+    }
+
+    for _, bad := range []string{
+        "1 2\n",
+        "*Vertices\n",
+        "*Vertices many\n",
+        "*Vertices 2\n*Matrix\n",
+        "*Vertices 2\n*Arcs\n1 3\n",
+        "*Vertices 2\n*Arcs\n1\n",
+        "*Vertices 2\n1 \"open\n",
+    } {
+        if _, err := ReadPajek(strings.NewReader(bad)); err == nil {
+            t.Errorf("reading %q did not fail", bad)
+        }
+    }
+}
@@ -0,0 +1,2373 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+// Queries are written in a subset of Cypher:
+//
+//  MATCH pattern, ... [WHERE condition]     one or more MATCH clauses
+//  RETURN [DISTINCT] expression [AS name], ...
+//  [ORDER BY expression [ASC | DESC], ...]
+//  [SKIP count] [LIMIT count]
+//
+// A pattern is a chain of nodes and edges, optionally named as a path
+// with p = (a)-->(b):
+//
+//  (n:Label:Other {key: value})    a node with all the labels and
+//                                  properties, every part optional
+//  -[r:TYPE|OTHER {key: value}]->  an edge of any of the types, also
+//                                  <-[...]- and -[...]- for either way,
+//                                  or --> <-- -- without brackets
+//  -[r:TYPE*1..3]->                one to three edges; *2 is exactly two,
+//                                  *..3 up to three, * any number
+//
+// Edge types are those of Edge.Type. An edge is used at most once in the
+// matches of a MATCH clause; a later clause sees the variables bound by
+// earlier ones. Expressions support
+//
+//  literals     12, 1.5, 'text', "text", true, false, null, [1, 2]
+//  access       n.key, n:Label, list[0]
+//  operators    + - * / %, = <> != < <= > >=, =~ (regular expression),
+//               STARTS WITH, ENDS WITH, CONTAINS, IN, IS [NOT] NULL,
+//               NOT, AND, OR, XOR
+//  aggregates   count(*), count([DISTINCT] x), sum, avg, min, max and
+//               collect, grouping by the other returned values
+//  functions    labels, type, startNode, endNode, nodes, relationships,
+//               length, size, coalesce, abs, toString, toInteger,
+//               toFloat, toLower, toUpper
+//
+// Properties are read with GetValue, so values set by typed setters
+// keep their type. Text that parses as a number compares, and takes part
+// in arithmetic other than +, as that number; two texts are only equal
+// if they are the same. In ORDER BY null sorts last, or first with DESC.
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+)
+
+// Query is a parsed query that can be run against any graph.
+type Query struct {
+    matches   []cypherMatch
+    distinct  bool
+    items     []cypherItem
+    order     []cypherOrder
+    skip      int
+    limit     int
+}
+
+// QueryResult is the table returned by a query. Cells hold nil, bool,
+// int64, float64, string, time.Time, []byte, []interface{}, *Node,
+// *Edge or Trail values.
+type QueryResult struct {
+    Columns  []string
+    Rows     [][]interface{}
+}
+
+type cypherMatch struct {
+    patterns  []cypherPattern
+    where     cypherExpr
+}
+
+// cypherPattern is a chain of nodes joined by one fewer edges.
+type cypherPattern struct {
+    path   string
+    nodes  []cypherNode
+    edges  []cypherEdge
+}
+
+type cypherNode struct {
+    name    string
+    labels  []string
+    props   []cypherProperty
+}
+
+// cypherEdge matches min to max edges, max -1 for no limit, or exactly
+// one edge when variable is false.
+type cypherEdge struct {
+    name      string
+    types     []string
+    direction Direction
+    props     []cypherProperty
+    variable  bool
+    min       int
+    max       int
+}
+
+type cypherProperty struct {
+    key    string
+    value  cypherExpr
+}
+
+type cypherItem struct {
+    expr       cypherExpr
+    name       string
+    aggregate  bool
+}
+
+type cypherOrder struct {
+    expr  cypherExpr
+    desc  bool
+}
+
+// Query parses and runs a query on the graph, see the grammar above.
+func (g *Graph) Query(text string) (*QueryResult, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+
+    q, err := ParseQuery(text)
+    if err != nil {
+        return nil, err
+    }
+    return g.Execute(q)
+}
+
+// Lexing
+
+const (
+    cypherEndToken = iota
+    cypherNameToken
+    cypherNumberToken
+    cypherStringToken
+    cypherSymbolToken
+)
+
+type cypherToken struct {
+    kind    int
+    text    string
+    quoted  bool
+    start   int
+    end     int
+}
+
+func cypherNameChar(c byte) bool {
+    return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func cypherDigit(c byte) bool {
+    return c >= '0' && c <= '9'
+}
+
+// cypherTokens splits a query into names, numbers, strings and symbols.
+// String tokens hold their unescaped text; the escapes are \n, \t, \r,
+// \b, \f, \\, \', \", \uXXXX and \UXXXXXXXX.
+func cypherTokens(src string) ([]cypherToken, error) {
+
+    tokens := make([]cypherToken, 0)
+    for i := 0; i < len(src); {
+        c := src[i]
+        start := i
+        switch {
+        case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+            i++
+            continue
+        case c == '/' && i+1 < len(src) && src[i+1] == '/':
+            for i < len(src) && src[i] != '\n' {
+                i++
+            }
+            continue
+        case cypherDigit(c):
+            for i < len(src) && cypherDigit(src[i]) {
+                i++
+            }
+            // a dot starts a fraction unless it is part of ..
+            if i+1 < len(src) && src[i] == '.' && cypherDigit(src[i+1]) {
+                i++
+                for i < len(src) && cypherDigit(src[i]) {
+                    i++
+                }
+            }
+            if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+                j := i + 1
+                if j < len(src) && (src[j] == '+' || src[j] == '-') {
+                    j++
+                }
+                if j < len(src) && cypherDigit(src[j]) {
+                    i = j
+                    for i < len(src) && cypherDigit(src[i]) {
+                        i++
+                    }
+                }
+            }
+            tokens = append(tokens, cypherToken{kind: cypherNumberToken, text: src[start:i], start: start, end: i})
+        case cypherNameChar(c):
+            for i < len(src) && cypherNameChar(src[i]) {
+                i++
+            }
+            tokens = append(tokens, cypherToken{kind: cypherNameToken, text: src[start:i], start: start, end: i})
+        case c == '`':
+            end := strings.IndexByte(src[i+1:], '`')
+            if end < 0 {
+                return nil, errors.New("Unterminated quoted name")
+            }
+            i += end + 2
+            tokens = append(tokens, cypherToken{kind: cypherNameToken, text: src[start+1 : i-1], quoted: true, start: start, end: i})
+        case c == '\'' || c == '"':
+            var b strings.Builder
+            i++
+            for {
+                if i >= len(src) {
+                    return nil, errors.New("Unterminated string")
+                }
+                if src[i] == c {
+                    i++
+                    break
+                }
+                if src[i] == '\\' && i+1 < len(src) {
+                    i++
+                    switch src[i] {
+                    case 'n':
+                        b.WriteByte('\n')
+                    case 't':
+                        b.WriteByte('\t')
+                    case 'r':
+                        b.WriteByte('\r')
+                    case 'b':
+                        b.WriteByte('\b')
+                    case 'f':
+                        b.WriteByte('\f')
+                    case '\\', '\'', '"':
+                        b.WriteByte(src[i])
+                    case 'u', 'U':
+                        size := 4
+                        if src[i] == 'U' {
+                            size = 8
+                        }
+                        if i+size >= len(src) {
+                            return nil, errors.New("Invalid escape \\" + src[i:])
+                        }
+                        r, err := strconv.ParseUint(src[i+1:i+1+size], 16, 32)
+                        if err != nil || !utf8.ValidRune(rune(r)) {
+                            return nil, errors.New("Invalid escape \\" + src[i:i+1+size])
+                        }
+                        b.WriteRune(rune(r))
+                        i += size
+                    default:
+                        return nil, errors.New("Invalid escape \\" + src[i:i+1])
+                    }
+                    i++
+                    continue
+                }
+                b.WriteByte(src[i])
+                i++
+            }
+            tokens = append(tokens, cypherToken{kind: cypherStringToken, text: b.String(), start: start, end: i})
+        default:
+            i++
+            if i < len(src) {
+                switch src[start : i+1] {
+                case "..", "<>", "<=", ">=", "!=", "=~":
+                    i++
+                }
+            }
+            if !strings.Contains("()[]{},:;.|*+-/%=<>!", src[start:i]) && len(src[start:i]) == 1 {
+                return nil, errors.New("Unexpected character " + strconv.Quote(src[start:i]))
+            }
+            tokens = append(tokens, cypherToken{kind: cypherSymbolToken, text: src[start:i], start: start, end: i})
+        }
+    }
+
+    tokens = append(tokens, cypherToken{kind: cypherEndToken, start: len(src), end: len(src)})
+    return tokens, nil
+}
+
+// Parsing
+
+// cypherParser is a recursive descent parser over the query tokens.
+type cypherParser struct {
+    src     string
+    tokens  []cypherToken
+    pos     int
+}
+
+// ParseQuery parses a query, see the grammar above.
+func ParseQuery(text string) (*Query, error) {
+
+    tokens, err := cypherTokens(text)
+    if err != nil {
+        return nil, errors.New("Query: " + err.Error())
+    }
+
+    p := &cypherParser{src: text, tokens: tokens}
+    q, err := p.query()
+    if err != nil {
+        line := 1 + strings.Count(text[:p.tokens[p.pos].start], "\n")
+        return nil, errors.New("Query line " + strconv.Itoa(line) + ": " + err.Error())
+    }
+
+    return q, nil
+}
+
+func (p *cypherParser) peek() cypherToken {
+    return p.tokens[p.pos]
+}
+
+func (p *cypherParser) next() cypherToken {
+    t := p.tokens[p.pos]
+    if t.kind != cypherEndToken {
+        p.pos++
+    }
+    return t
+}
+
+// is reports whether the next tokens are the given symbols.
+func (p *cypherParser) is(symbols ...string) bool {
+    for i, s := range symbols {
+        if p.pos+i >= len(p.tokens) {
+            return false
+        }
+        t := p.tokens[p.pos+i]
+        if t.kind != cypherSymbolToken || t.text != s {
+            return false
+        }
+    }
+    return true
+}
+
+func (p *cypherParser) accept(symbol string) bool {
+    if p.is(symbol) {
+        p.pos++
+        return true
+    }
+    return false
+}
+
+func (p *cypherParser) expect(symbol string) error {
+    if !p.accept(symbol) {
+        return errors.New("Expected " + strconv.Quote(symbol) + " at " + p.describe())
+    }
+    return nil
+}
+
+// isKeyword reports whether the next tokens are the given case
+// insensitive words.
+func (p *cypherParser) isKeyword(words ...string) bool {
+    for i, w := range words {
+        if p.pos+i >= len(p.tokens) {
+            return false
+        }
+        t := p.tokens[p.pos+i]
+        if t.kind != cypherNameToken || t.quoted || !strings.EqualFold(t.text, w) {
+            return false
+        }
+    }
+    return true
+}
+
+func (p *cypherParser) acceptKeyword(words ...string) bool {
+    if p.isKeyword(words...) {
+        p.pos += len(words)
+        return true
+    }
+    return false
+}
+
+func (p *cypherParser) describe() string {
+    t := p.peek()
+    if t.kind == cypherEndToken {
+        return "end of query"
+    }
+    return strconv.Quote(p.src[t.start:t.end])
+}
+
+func (p *cypherParser) name() (string, error) {
+    t := p.peek()
+    if t.kind != cypherNameToken {
+        return "", errors.New("Expected a name at " + p.describe())
+    }
+    p.pos++
+    return t.text, nil
+}
+
+func (p *cypherParser) count() (int, error) {
+    t := p.peek()
+    v, err := strconv.Atoi(t.text)
+    if t.kind != cypherNumberToken || err != nil {
+        return 0, errors.New("Expected a whole number at " + p.describe())
+    }
+    p.pos++
+    return v, nil
+}
+
+func (p *cypherParser) query() (*Query, error) {
+
+    q := &Query{limit: -1}
+    if !p.isKeyword("MATCH") {
+        return nil, errors.New("Expected MATCH at " + p.describe())
+    }
+
+    for p.acceptKeyword("MATCH") {
+        var m cypherMatch
+        for {
+            pattern, err := p.pattern()
+            if err != nil {
+                return nil, err
+            }
+            m.patterns = append(m.patterns, pattern)
+            if !p.accept(",") {
+                break
+            }
+        }
+        if p.acceptKeyword("WHERE") {
+            where, err := p.expr()
+            if err != nil {
+                return nil, err
+            }
+            m.where = where
+        }
+        q.matches = append(q.matches, m)
+    }
+
+    if !p.acceptKeyword("RETURN") {
+        return nil, errors.New("Expected RETURN at " + p.describe())
+    }
+    q.distinct = p.acceptKeyword("DISTINCT")
+    for {
+        start := p.peek().start
+        expr, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        item := cypherItem{expr: expr, aggregate: cypherAggregates(expr)}
+        item.name = strings.TrimSpace(p.src[start:p.tokens[p.pos-1].end])
+        if p.acceptKeyword("AS") {
+            if item.name, err = p.name(); err != nil {
+                return nil, err
+            }
+        }
+        q.items = append(q.items, item)
+        if !p.accept(",") {
+            break
+        }
+    }
+
+    if p.acceptKeyword("ORDER", "BY") {
+        for {
+            expr, err := p.expr()
+            if err != nil {
+                return nil, err
+            }
+            order := cypherOrder{expr: expr}
+            if p.acceptKeyword("DESC") || p.acceptKeyword("DESCENDING") {
+                order.desc = true
+            } else if !p.acceptKeyword("ASC") {
+                p.acceptKeyword("ASCENDING")
+            }
+            q.order = append(q.order, order)
+            if !p.accept(",") {
+                break
+            }
+        }
+    }
+
+    var err error
+    if p.acceptKeyword("SKIP") {
+        if q.skip, err = p.count(); err != nil {
+            return nil, err
+        }
+    }
+    if p.acceptKeyword("LIMIT") {
+        if q.limit, err = p.count(); err != nil {
+            return nil, err
+        }
+    }
+
+    p.accept(";")
+    if p.peek().kind != cypherEndToken {
+        return nil, errors.New("Unexpected " + p.describe())
+    }
+    return q, nil
+}
+
+func (p *cypherParser) pattern() (cypherPattern, error) {
+
+    var pattern cypherPattern
+    if p.peek().kind == cypherNameToken && p.tokens[p.pos+1].kind == cypherSymbolToken && p.tokens[p.pos+1].text == "=" {
+        pattern.path = p.next().text
+        p.next()
+    }
+
+    node, err := p.nodePattern()
+    if err != nil {
+        return pattern, err
+    }
+    pattern.nodes = append(pattern.nodes, node)
+
+    for p.is("-") || p.is("<", "-") {
+        edge, err := p.edgePattern()
+        if err != nil {
+            return pattern, err
+        }
+        node, err := p.nodePattern()
+        if err != nil {
+            return pattern, err
+        }
+        pattern.edges = append(pattern.edges, edge)
+        pattern.nodes = append(pattern.nodes, node)
+    }
+
+    return pattern, nil
+}
+
+func (p *cypherParser) nodePattern() (cypherNode, error) {
+
+    var node cypherNode
+    if err := p.expect("("); err != nil {
+        return node, err
+    }
+    if p.peek().kind == cypherNameToken {
+        node.name = p.next().text
+    }
+    for p.accept(":") {
+        label, err := p.name()
+        if err != nil {
+            return node, err
+        }
+        node.labels = append(node.labels, label)
+    }
+    if p.is("{") {
+        props, err := p.properties()
+        if err != nil {
+            return node, err
+        }
+        node.props = props
+    }
+
+    return node, p.expect(")")
+}
+
+func (p *cypherParser) edgePattern() (cypherEdge, error) {
+
+    edge := cypherEdge{min: 1, max: 1}
+    left := p.accept("<")
+    if err := p.expect("-"); err != nil {
+        return edge, err
+    }
+
+    if p.accept("[") {
+        if p.peek().kind == cypherNameToken {
+            edge.name = p.next().text
+        }
+        if p.accept(":") {
+            for {
+                t, err := p.name()
+                if err != nil {
+                    return edge, err
+                }
+                edge.types = append(edge.types, t)
+                if !p.accept("|") {
+                    break
+                }
+                p.accept(":")
+            }
+        }
+        if p.accept("*") {
+            edge.variable = true
+            edge.max = -1
+            var err error
+            if p.peek().kind == cypherNumberToken {
+                if edge.min, err = p.count(); err != nil {
+                    return edge, err
+                }
+                edge.max = edge.min
+            }
+            if p.accept("..") {
+                edge.max = -1
+                if p.peek().kind == cypherNumberToken {
+                    if edge.max, err = p.count(); err != nil {
+                        return edge, err
+                    }
+                }
+            }
+            if edge.max >= 0 && edge.max < edge.min {
+                return edge, errors.New("Edge length range is empty")
+            }
+        }
+        if p.is("{") {
+            props, err := p.properties()
+            if err != nil {
+                return edge, err
+            }
+            edge.props = props
+        }
+        if err := p.expect("]"); err != nil {
+            return edge, err
+        }
+    }
+
+    if err := p.expect("-"); err != nil {
+        return edge, err
+    }
+    right := p.accept(">")
+
+    switch {
+    case left && right:
+        return edge, errors.New("Edge cannot point both ways")
+    case left:
+        edge.direction = Incoming
+    case right:
+        edge.direction = Outgoing
+    default:
+        edge.direction = Both
+    }
+    return edge, nil
+}
+
+func (p *cypherParser) properties() ([]cypherProperty, error) {
+
+    props := make([]cypherProperty, 0)
+    if err := p.expect("{"); err != nil {
+        return nil, err
+    }
+    if p.accept("}") {
+        return props, nil
+    }
+
+    for {
+        key, err := p.name()
+        if err != nil {
+            return nil, err
+        }
+        if err := p.expect(":"); err != nil {
+            return nil, err
+        }
+        value, err := p.expr()
+        if err != nil {
+            return nil, err
+        }
+        props = append(props, cypherProperty{key, value})
+        if !p.accept(",") {
+            break
+        }
+    }
+
+    return props, p.expect("}")
+}
+
+// expr parses an expression, from the loosest binding operators to the
+// tightest.
+func (p *cypherParser) expr() (cypherExpr, error) {
+    return p.binary(0)
+}
+
+var cypherLevels = [][]string{
+    {"OR"},
+    {"XOR"},
+    {"AND"},
+}
+
+// binary parses the boolean operators of a level and those above it.
+func (p *cypherParser) binary(level int) (cypherExpr, error) {
+
+    if level == len(cypherLevels) {
+        return p.not()
+    }
+
+    left, err := p.binary(level + 1)
+    if err != nil {
+        return nil, err
+    }
+    for {
+        op := ""
+        for _, word := range cypherLevels[level] {
+            if p.acceptKeyword(word) {
+                op = word
+            }
+        }
+        if len(op) == 0 {
+            return left, nil
+        }
+        right, err := p.binary(level + 1)
+        if err != nil {
+            return nil, err
+        }
+        left = &cypherBinary{op: op, left: left, right: right}
+    }
+}
+
+func (p *cypherParser) not() (cypherExpr, error) {
+    if p.acceptKeyword("NOT") {
+        x, err := p.not()
+        if err != nil {
+            return nil, err
+        }
+        return &cypherUnary{op: "NOT", x: x}, nil
+    }
+    return p.comparison()
+}
+
+func (p *cypherParser) comparison() (cypherExpr, error) {
+
+    left, err := p.additive()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        op := ""
+        switch {
+        case p.is("=") || p.is("<>") || p.is("!=") || p.is("<") || p.is("<=") ||
+            p.is(">") || p.is(">=") || p.is("=~"):
+            op = p.next().text
+        case p.acceptKeyword("STARTS", "WITH"):
+            op = "STARTS WITH"
+        case p.acceptKeyword("ENDS", "WITH"):
+            op = "ENDS WITH"
+        case p.acceptKeyword("CONTAINS"):
+            op = "CONTAINS"
+        case p.acceptKeyword("IN"):
+            op = "IN"
+        case p.acceptKeyword("IS", "NOT", "NULL"):
+            left = &cypherUnary{op: "IS NOT NULL", x: left}
+            continue
+        case p.acceptKeyword("IS", "NULL"):
+            left = &cypherUnary{op: "IS NULL", x: left}
+            continue
+        default:
+            return left, nil
+        }
+        right, err := p.additive()
+        if err != nil {
+            return nil, err
+        }
+        b := &cypherBinary{op: op, left: left, right: right}
+        // compile constant regular expressions once
+        if pattern, ok := right.(*cypherLiteral); ok && op == "=~" {
+            if text, ok := pattern.value.(string); ok {
+                if b.re, err = regexp.Compile("^(?:" + text + ")$"); err != nil {
+                    return nil, err
+                }
+            }
+        }
+        left = b
+    }
+}
+
+func (p *cypherParser) additive() (cypherExpr, error) {
+
+    left, err := p.multiplicative()
+    if err != nil {
+        return nil, err
+    }
+    for p.is("+") || p.is("-") {
+        op := p.next().text
+        right, err := p.multiplicative()
+        if err != nil {
+            return nil, err
+        }
+        left = &cypherBinary{op: op, left: left, right: right}
+    }
+
+    return left, nil
+}
+
+func (p *cypherParser) multiplicative() (cypherExpr, error) {
+
+    left, err := p.unary()
+    if err != nil {
+        return nil, err
+    }
+    for p.is("*") || p.is("/") || p.is("%") {
+        op := p.next().text
+        right, err := p.unary()
+        if err != nil {
+            return nil, err
+        }
+        left = &cypherBinary{op: op, left: left, right: right}
+    }
+
+    return left, nil
+}
+
+func (p *cypherParser) unary() (cypherExpr, error) {
+    if p.accept("-") {
+        x, err := p.unary()
+        if err != nil {
+            return nil, err
+        }
+        return &cypherUnary{op: "-", x: x}, nil
+    }
+    p.accept("+")
+    return p.postfix()
+}
+
+// postfix parses an atom followed by property lookups, label tests and
+// list indexes.
+func (p *cypherParser) postfix() (cypherExpr, error) {
+
+    x, err := p.atom()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        switch {
+        case p.accept("."):
+            key, err := p.name()
+            if err != nil {
+                return nil, err
+            }
+            x = &cypherLookup{x: x, key: key}
+        case p.is(":"):
+            labels := make([]string, 0)
+            for p.accept(":") {
+                label, err := p.name()
+                if err != nil {
+                    return nil, err
+                }
+                labels = append(labels, label)
+            }
+            x = &cypherLabels{x: x, labels: labels}
+        case p.accept("["):
+            index, err := p.expr()
+            if err != nil {
+                return nil, err
+            }
+            if err := p.expect("]"); err != nil {
+                return nil, err
+            }
+            x = &cypherBinary{op: "[]", left: x, right: index}
+        default:
+            return x, nil
+        }
+    }
+}
+
+func (p *cypherParser) atom() (cypherExpr, error) {
+
+    t := p.peek()
+    switch t.kind {
+    case cypherNumberToken:
+        p.pos++
+        if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+            return &cypherLiteral{i}, nil
+        }
+        f, err := strconv.ParseFloat(t.text, 64)
+        if err != nil {
+            return nil, errors.New("Invalid number " + t.text)
+        }
+        return &cypherLiteral{f}, nil
+    case cypherStringToken:
+        p.pos++
+        return &cypherLiteral{t.text}, nil
+    case cypherSymbolToken:
+        if p.accept("(") {
+            x, err := p.expr()
+            if err != nil {
+                return nil, err
+            }
+            return x, p.expect(")")
+        }
+        if p.accept("[") {
+            list := &cypherList{}
+            if p.accept("]") {
+                return list, nil
+            }
+            for {
+                item, err := p.expr()
+                if err != nil {
+                    return nil, err
+                }
+                list.items = append(list.items, item)
+                if !p.accept(",") {
+                    break
+                }
+            }
+            return list, p.expect("]")
+        }
+    case cypherNameToken:
+        switch {
+        case p.acceptKeyword("TRUE"):
+            return &cypherLiteral{true}, nil
+        case p.acceptKeyword("FALSE"):
+            return &cypherLiteral{false}, nil
+        case p.acceptKeyword("NULL"):
+            return &cypherLiteral{nil}, nil
+        }
+        p.pos++
+        if !p.accept("(") {
+            return &cypherVariable{t.text}, nil
+        }
+        call := &cypherCall{name: strings.ToLower(t.text)}
+        if _, ok := cypherFunctions[call.name]; !ok && !cypherAggregate(call.name) {
+            return nil, errors.New("Unknown function " + t.text)
+        }
+        if p.accept("*") {
+            if call.name != "count" {
+                return nil, errors.New("Only count takes *")
+            }
+            call.star = true
+            return call, p.expect(")")
+        }
+        call.distinct = p.acceptKeyword("DISTINCT")
+        if p.accept(")") {
+            return call, nil
+        }
+        for {
+            arg, err := p.expr()
+            if err != nil {
+                return nil, err
+            }
+            call.args = append(call.args, arg)
+            if !p.accept(",") {
+                break
+            }
+        }
+        return call, p.expect(")")
+    }
+
+    return nil, errors.New("Unexpected " + p.describe())
+}
+
+// Expressions
+
+// cypherEnv holds the variables of a row. When a RETURN clause
+// aggregates, group holds the rows being aggregated.
+type cypherEnv struct {
+    vars   map[string]interface{}
+    group  []*cypherEnv
+}
+
+type cypherExpr interface {
+    eval(env *cypherEnv) (interface{}, error)
+}
+
+type cypherLiteral struct {
+    value  interface{}
+}
+
+type cypherList struct {
+    items  []cypherExpr
+}
+
+type cypherVariable struct {
+    name  string
+}
+
+type cypherLookup struct {
+    x    cypherExpr
+    key  string
+}
+
+type cypherLabels struct {
+    x       cypherExpr
+    labels  []string
+}
+
+type cypherUnary struct {
+    op  string
+    x   cypherExpr
+}
+
+type cypherBinary struct {
+    op     string
+    left   cypherExpr
+    right  cypherExpr
+    re     *regexp.Regexp
+}
+
+type cypherCall struct {
+    name      string
+    args      []cypherExpr
+    distinct  bool
+    star      bool
+}
+
+func (x *cypherLiteral) eval(env *cypherEnv) (interface{}, error) {
+    return x.value, nil
+}
+
+func (x *cypherList) eval(env *cypherEnv) (interface{}, error) {
+
+    out := make([]interface{}, len(x.items))
+    for i, item := range x.items {
+        v, err := item.eval(env)
+        if err != nil {
+            return nil, err
+        }
+        out[i] = v
+    }
+
+    return out, nil
+}
+
+func (x *cypherVariable) eval(env *cypherEnv) (interface{}, error) {
+    v, ok := env.vars[x.name]
+    if !ok {
+        return nil, errors.New("Unknown variable " + x.name)
+    }
+    return v, nil
+}
+
+func (x *cypherLookup) eval(env *cypherEnv) (interface{}, error) {
+
+    v, err := x.x.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    var value Value
+    var ok bool
+    switch v := v.(type) {
+    case nil:
+        return nil, nil
+    case *Node:
+        value, ok = v.GetValue(x.key)
+    case *Edge:
+        value, ok = v.GetValue(x.key)
+    default:
+        return nil, errors.New("Cannot read property " + x.key + " of " + cypherTypeName(v))
+    }
+
+    if !ok {
+        return nil, nil
+    }
+    return cypherFromValue(value), nil
+}
+
+func (x *cypherLabels) eval(env *cypherEnv) (interface{}, error) {
+
+    v, err := x.x.eval(env)
+    if err != nil || v == nil {
+        return nil, err
+    }
+    n, ok := v.(*Node)
+    if !ok {
+        return nil, errors.New("Labels can only be tested on nodes")
+    }
+    for _, label := range x.labels {
+        if !n.HasLabel(label) {
+            return false, nil
+        }
+    }
+
+    return true, nil
+}
+
+func (x *cypherUnary) eval(env *cypherEnv) (interface{}, error) {
+
+    v, err := x.x.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    switch x.op {
+    case "IS NULL":
+        return v == nil, nil
+    case "IS NOT NULL":
+        return v != nil, nil
+    case "NOT":
+        b, known, err := cypherBool(v)
+        if err != nil || !known {
+            return nil, err
+        }
+        return !b, nil
+    }
+
+    // unary minus
+    switch n := v.(type) {
+    case nil:
+        return nil, nil
+    case int64:
+        return -n, nil
+    case float64:
+        return -n, nil
+    }
+    if n, ok := cypherNumeric(v); ok {
+        return cypherArithmetic("-", int64(0), n)
+    }
+    return nil, errors.New("Cannot negate " + cypherTypeName(v))
+}
+
+func (x *cypherBinary) eval(env *cypherEnv) (interface{}, error) {
+
+    left, err := x.left.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    // the boolean operators short-circuit
+    switch x.op {
+    case "AND", "OR":
+        l, lknown, err := cypherBool(left)
+        if err != nil {
+            return nil, err
+        }
+        if lknown && l == (x.op == "OR") {
+            return l, nil
+        }
+        right, err := x.right.eval(env)
+        if err != nil {
+            return nil, err
+        }
+        r, rknown, err := cypherBool(right)
+        if err != nil {
+            return nil, err
+        }
+        if rknown && r == (x.op == "OR") {
+            return r, nil
+        }
+        if !lknown || !rknown {
+            return nil, nil
+        }
+        return r, nil
+    }
+
+    right, err := x.right.eval(env)
+    if err != nil {
+        return nil, err
+    }
+
+    switch x.op {
+    case "XOR":
+        l, lknown, err := cypherBool(left)
+        if err != nil {
+            return nil, err
+        }
+        r, rknown, err := cypherBool(right)
+        if err != nil || !lknown || !rknown {
+            return nil, err
+        }
+        return l != r, nil
+    case "=":
+        return cypherEqual(left, right), nil
+    case "<>", "!=":
+        eq := cypherEqual(left, right)
+        if eq == nil {
+            return nil, nil
+        }
+        return !eq.(bool), nil
+    case "<", "<=", ">", ">=":
+        c, ok := cypherCompare(left, right)
+        if !ok {
+            return nil, nil
+        }
+        switch x.op {
+        case "<":
+            return c < 0, nil
+        case "<=":
+            return c <= 0, nil
+        case ">":
+            return c > 0, nil
+        }
+        return c >= 0, nil
+    case "=~", "STARTS WITH", "ENDS WITH", "CONTAINS":
+        if left == nil || right == nil {
+            return nil, nil
+        }
+        l, lok := left.(string)
+        r, rok := right.(string)
+        if !lok || !rok {
+            return nil, errors.New(x.op + " needs strings")
+        }
+        switch x.op {
+        case "STARTS WITH":
+            return strings.HasPrefix(l, r), nil
+        case "ENDS WITH":
+            return strings.HasSuffix(l, r), nil
+        case "CONTAINS":
+            return strings.Contains(l, r), nil
+        }
+        re := x.re
+        if re == nil {
+            if re, err = regexp.Compile("^(?:" + r + ")$"); err != nil {
+                return nil, err
+            }
+        }
+        return re.MatchString(l), nil
+    case "IN":
+        if right == nil {
+            return nil, nil
+        }
+        list, ok := right.([]interface{})
+        if !ok {
+            return nil, errors.New("IN needs a list")
+        }
+        var out interface{} = false
+        for _, item := range list {
+            eq := cypherEqual(left, item)
+            if eq == true {
+                return true, nil
+            }
+            if eq == nil {
+                out = nil
+            }
+        }
+        return out, nil
+    case "[]":
+        if left == nil || right == nil {
+            return nil, nil
+        }
+        list, ok := left.([]interface{})
+        i, iok := right.(int64)
+        if !ok || !iok {
+            return nil, errors.New("Only lists can be indexed, by integers")
+        }
+        if i < 0 {
+            i += int64(len(list))
+        }
+        if i < 0 || i >= int64(len(list)) {
+            return nil, nil
+        }
+        return list[i], nil
+    }
+
+    return cypherArithmetic(x.op, left, right)
+}
+
+func (x *cypherCall) eval(env *cypherEnv) (interface{}, error) {
+
+    if cypherAggregate(x.name) {
+        return x.aggregate(env)
+    }
+
+    args := make([]interface{}, len(x.args))
+    for i, arg := range x.args {
+        v, err := arg.eval(env)
+        if err != nil {
+            return nil, err
+        }
+        args[i] = v
+    }
+
+    f := cypherFunctions[x.name]
+    if x.name != "coalesce" && len(args) != 1 {
+        return nil, errors.New(x.name + " takes one argument")
+    }
+    return f(args)
+}
+
+// aggregate evaluates an aggregate function over the rows of a group.
+func (x *cypherCall) aggregate(env *cypherEnv) (interface{}, error) {
+
+    if env.group == nil {
+        return nil, errors.New(x.name + " can only be used in RETURN")
+    }
+    if !x.star && len(x.args) != 1 {
+        return nil, errors.New(x.name + " takes one argument")
+    }
+
+    values := make([]interface{}, 0, len(env.group))
+    seen := make(map[string]bool)
+    for _, row := range env.group {
+        if x.star {
+            values = append(values, true)
+            continue
+        }
+        v, err := x.args[0].eval(&cypherEnv{vars: row.vars})
+        if err != nil {
+            return nil, err
+        }
+        if v == nil {
+            continue
+        }
+        if x.distinct {
+            key := cypherKey(v)
+            if seen[key] {
+                continue
+            }
+            seen[key] = true
+        }
+        values = append(values, v)
+    }
+
+    switch x.name {
+    case "count":
+        return int64(len(values)), nil
+    case "collect":
+        return values, nil
+    case "min", "max":
+        var best interface{}
+        for _, v := range values {
+            c := cypherOrderOf(v, best)
+            if best == nil || (x.name == "min" && c < 0) || (x.name == "max" && c > 0) {
+                best = v
+            }
+        }
+        return best, nil
+    }
+
+    // sum and avg
+    var sum interface{} = int64(0)
+    for _, v := range values {
+        n, ok := cypherNumeric(v)
+        if !ok {
+            return nil, errors.New(x.name + " needs numbers")
+        }
+        var err error
+        if sum, err = cypherArithmetic("+", sum, n); err != nil {
+            return nil, err
+        }
+    }
+    if x.name == "sum" {
+        return sum, nil
+    }
+    if len(values) == 0 {
+        return nil, nil
+    }
+    f, _ := cypherFloat(sum)
+    return f / float64(len(values)), nil
+}
+
+func cypherAggregate(name string) bool {
+    switch name {
+    case "count", "sum", "avg", "min", "max", "collect":
+        return true
+    }
+    return false
+}
+
+// cypherAggregates reports whether an expression uses an aggregate.
+func cypherAggregates(x cypherExpr) bool {
+    switch x := x.(type) {
+    case *cypherCall:
+        if cypherAggregate(x.name) {
+            return true
+        }
+        for _, arg := range x.args {
+            if cypherAggregates(arg) {
+                return true
+            }
+        }
+    case *cypherList:
+        for _, item := range x.items {
+            if cypherAggregates(item) {
+                return true
+            }
+        }
+    case *cypherLookup:
+        return cypherAggregates(x.x)
+    case *cypherLabels:
+        return cypherAggregates(x.x)
+    case *cypherUnary:
+        return cypherAggregates(x.x)
+    case *cypherBinary:
+        return cypherAggregates(x.left) || cypherAggregates(x.right)
+    }
+    return false
+}
+
+var cypherFunctions = map[string]func([]interface{}) (interface{}, error){
+    "labels": func(args []interface{}) (interface{}, error) {
+        n, ok := args[0].(*Node)
+        if !ok {
+            return nil, cypherArgError("labels", "a node", args[0])
+        }
+        out := make([]interface{}, 0)
+        for _, label := range n.Labels() {
+            out = append(out, label)
+        }
+        return out, nil
+    },
+    "type": func(args []interface{}) (interface{}, error) {
+        e, ok := args[0].(*Edge)
+        if !ok {
+            return nil, cypherArgError("type", "an edge", args[0])
+        }
+        return e.Type(), nil
+    },
+    "startnode": func(args []interface{}) (interface{}, error) {
+        e, ok := args[0].(*Edge)
+        if !ok {
+            return nil, cypherArgError("startNode", "an edge", args[0])
+        }
+        return e.ParentNode, nil
+    },
+    "endnode": func(args []interface{}) (interface{}, error) {
+        e, ok := args[0].(*Edge)
+        if !ok {
+            return nil, cypherArgError("endNode", "an edge", args[0])
+        }
+        return e.ChildNode, nil
+    },
+    "nodes": func(args []interface{}) (interface{}, error) {
+        t, ok := args[0].(Trail)
+        if !ok {
+            return nil, cypherArgError("nodes", "a path", args[0])
+        }
+        out := make([]interface{}, len(t.Nodes))
+        for i, n := range t.Nodes {
+            out[i] = n
+        }
+        return out, nil
+    },
+    "relationships": func(args []interface{}) (interface{}, error) {
+        t, ok := args[0].(Trail)
+        if !ok {
+            return nil, cypherArgError("relationships", "a path", args[0])
+        }
+        out := make([]interface{}, len(t.Edges))
+        for i, e := range t.Edges {
+            out[i] = e
+        }
+        return out, nil
+    },
+    "length": func(args []interface{}) (interface{}, error) {
+        if t, ok := args[0].(Trail); ok {
+            return int64(len(t.Edges)), nil
+        }
+        return cypherSize(args[0])
+    },
+    "size": func(args []interface{}) (interface{}, error) {
+        return cypherSize(args[0])
+    },
+    "coalesce": func(args []interface{}) (interface{}, error) {
+        for _, v := range args {
+            if v != nil {
+                return v, nil
+            }
+        }
+        return nil, nil
+    },
+    "abs": func(args []interface{}) (interface{}, error) {
+        switch v := args[0].(type) {
+        case nil:
+            return nil, nil
+        case int64:
+            if v < 0 {
+                return -v, nil
+            }
+            return v, nil
+        }
+        f, ok := cypherFloat(args[0])
+        if !ok {
+            return nil, cypherArgError("abs", "a number", args[0])
+        }
+        return math.Abs(f), nil
+    },
+    "tostring": func(args []interface{}) (interface{}, error) {
+        if args[0] == nil {
+            return nil, nil
+        }
+        return cypherString(args[0]), nil
+    },
+    "tointeger": func(args []interface{}) (interface{}, error) {
+        if s, ok := args[0].(string); ok {
+            args[0], _ = cypherNumeric(s)
+        }
+        switch v := args[0].(type) {
+        case int64:
+            return v, nil
+        case float64:
+            return int64(v), nil
+        }
+        return nil, nil
+    },
+    "tofloat": func(args []interface{}) (interface{}, error) {
+        if f, ok := cypherFloat(args[0]); ok {
+            return f, nil
+        }
+        if s, ok := args[0].(string); ok {
+            if n, ok := cypherNumeric(s); ok {
+                f, _ := cypherFloat(n)
+                return f, nil
+            }
+        }
+        return nil, nil
+    },
+    "tolower": func(args []interface{}) (interface{}, error) {
+        if args[0] == nil {
+            return nil, nil
+        }
+        s, ok := args[0].(string)
+        if !ok {
+            return nil, cypherArgError("toLower", "a string", args[0])
+        }
+        return strings.ToLower(s), nil
+    },
+    "toupper": func(args []interface{}) (interface{}, error) {
+        if args[0] == nil {
+            return nil, nil
+        }
+        s, ok := args[0].(string)
+        if !ok {
+            return nil, cypherArgError("toUpper", "a string", args[0])
+        }
+        return strings.ToUpper(s), nil
+    },
+}
+
+func cypherArgError(name string, want string, got interface{}) error {
+    return errors.New(name + " needs " + want + ", not " + cypherTypeName(got))
+}
+
+func cypherSize(v interface{}) (interface{}, error) {
+    switch v := v.(type) {
+    case nil:
+        return nil, nil
+    case string:
+        return int64(len([]rune(v))), nil
+    case []interface{}:
+        return int64(len(v)), nil
+    }
+    return nil, cypherArgError("size", "a string or list", v)
+}
+
+// Values
+
+func cypherTypeName(v interface{}) string {
+    switch v.(type) {
+    case nil:
+        return "null"
+    case bool:
+        return "a boolean"
+    case int64:
+        return "an integer"
+    case float64:
+        return "a float"
+    case string:
+        return "a string"
+    case time.Time:
+        return "a time"
+    case []byte:
+        return "bytes"
+    case []interface{}:
+        return "a list"
+    case *Node:
+        return "a node"
+    case *Edge:
+        return "an edge"
+    case Trail:
+        return "a path"
+    }
+    return "an unknown value"
+}
+
+// cypherFromValue converts a property value to a query value.
+func cypherFromValue(v Value) interface{} {
+    switch v.Kind() {
+    case IntKind:
+        i, _ := v.Int()
+        return i
+    case FloatKind:
+        f, _ := v.Float()
+        return f
+    case BoolKind:
+        b, _ := v.Bool()
+        return b
+    case TimeKind:
+        t, _ := v.Time()
+        return t
+    case BytesKind:
+        b, _ := v.Bytes()
+        return b
+    case ListKind:
+        items, _ := v.List()
+        out := make([]interface{}, len(items))
+        for i, item := range items {
+            out[i] = cypherFromValue(item)
+        }
+        return out
+    }
+    return v.String()
+}
+
+// cypherBool returns a boolean and whether it is known, false for null.
+func cypherBool(v interface{}) (bool, bool, error) {
+    switch v := v.(type) {
+    case nil:
+        return false, false, nil
+    case bool:
+        return v, true, nil
+    }
+    return false, false, errors.New("Expected a boolean, not " + cypherTypeName(v))
+}
+
+func cypherFloat(v interface{}) (float64, bool) {
+    switch v := v.(type) {
+    case int64:
+        return float64(v), true
+    case float64:
+        return v, true
+    }
+    return 0, false
+}
+
+// cypherNumeric returns a number as is and text that parses as a finite
+// number as that number.
+func cypherNumeric(v interface{}) (interface{}, bool) {
+    switch v := v.(type) {
+    case int64, float64:
+        return v, true
+    case string:
+        s := strings.TrimSpace(v)
+        if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+            return i, true
+        }
+        if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+            return f, true
+        }
+    }
+    return nil, false
+}
+
+func cypherString(v interface{}) string {
+    switch v := v.(type) {
+    case string:
+        return v
+    case int64:
+        return strconv.FormatInt(v, 10)
+    case float64:
+        return strconv.FormatFloat(v, 'g', -1, 64)
+    case bool:
+        return strconv.FormatBool(v)
+    case time.Time:
+        return v.Format(time.RFC3339Nano)
+    case []byte:
+        return BytesValue(v).String()
+    case *Node:
+        return v.GetProperty("id")
+    case *Edge:
+        return v.GetProperty("id")
+    }
+    return cypherKey(v)
+}
+
+func cypherArithmetic(op string, left interface{}, right interface{}) (interface{}, error) {
+
+    if left == nil || right == nil {
+        return nil, nil
+    }
+
+    if op == "+" {
+        if l, ok := left.([]interface{}); ok {
+            if r, ok := right.([]interface{}); ok {
+                return append(append([]interface{}{}, l...), r...), nil
+            }
+            return append(append([]interface{}{}, l...), right), nil
+        }
+        _, lstr := left.(string)
+        _, rstr := right.(string)
+        if lstr && rstr {
+            return left.(string) + right.(string), nil
+        }
+        if lstr || rstr {
+            if _, ok := cypherNumeric(left); !ok || (lstr && rstr) {
+                return cypherString(left) + cypherString(right), nil
+            }
+            if _, ok := cypherNumeric(right); !ok {
+                return cypherString(left) + cypherString(right), nil
+            }
+        }
+    }
+
+    l, lok := cypherNumeric(left)
+    r, rok := cypherNumeric(right)
+    if !lok || !rok {
+        return nil, errors.New("Cannot apply " + op + " to " + cypherTypeName(left) + " and " + cypherTypeName(right))
+    }
+
+    li, lint := l.(int64)
+    ri, rint := r.(int64)
+    if lint && rint {
+        switch op {
+        case "+":
+            return li + ri, nil
+        case "-":
+            return li - ri, nil
+        case "*":
+            return li * ri, nil
+        }
+        if ri == 0 {
+            return nil, errors.New("Division by zero")
+        }
+        if op == "/" {
+            return li / ri, nil
+        }
+        return li % ri, nil
+    }
+
+    lf, _ := cypherFloat(l)
+    rf, _ := cypherFloat(r)
+    switch op {
+    case "+":
+        return lf + rf, nil
+    case "-":
+        return lf - rf, nil
+    case "*":
+        return lf * rf, nil
+    case "/":
+        return lf / rf, nil
+    }
+    return math.Mod(lf, rf), nil
+}
+
+// cypherCompare orders two values of comparable types, reading text
+// that parses as a number as that number.
+func cypherCompare(a interface{}, b interface{}) (int, bool) {
+
+    if a == nil || b == nil {
+        return 0, false
+    }
+
+    if an, ok := cypherNumeric(a); ok {
+        if bn, ok := cypherNumeric(b); ok {
+            ai, aint := an.(int64)
+            bi, bint := bn.(int64)
+            if aint && bint {
+                switch {
+                case ai < bi:
+                    return -1, true
+                case ai > bi:
+                    return 1, true
+                }
+                return 0, true
+            }
+            af, _ := cypherFloat(an)
+            bf, _ := cypherFloat(bn)
+            switch {
+            case af < bf:
+                return -1, true
+            case af > bf:
+                return 1, true
+            case af == bf:
+                return 0, true
+            }
+            return 0, false
+        }
+    }
+
+    switch a := a.(type) {
+    case string:
+        if b, ok := b.(string); ok {
+            return strings.Compare(a, b), true
+        }
+    case bool:
+        if b, ok := b.(bool); ok {
+            switch {
+            case a == b:
+                return 0, true
+            case b:
+                return -1, true
+            }
+            return 1, true
+        }
+    case time.Time:
+        if b, ok := b.(time.Time); ok {
+            switch {
+            case a.Before(b):
+                return -1, true
+            case a.After(b):
+                return 1, true
+            }
+            return 0, true
+        }
+    }
+
+    return 0, false
+}
+
+// cypherEqual returns true, false or nil when either side is null.
+// Two texts are only equal if they are the same.
+func cypherEqual(a interface{}, b interface{}) interface{} {
+
+    if a == nil || b == nil {
+        return nil
+    }
+
+    switch a := a.(type) {
+    case string:
+        if b, ok := b.(string); ok {
+            return a == b
+        }
+    case []interface{}:
+        b, ok := b.([]interface{})
+        if !ok || len(a) != len(b) {
+            return false
+        }
+        var out interface{} = true
+        for i := range a {
+            eq := cypherEqual(a[i], b[i])
+            if eq == false {
+                return false
+            }
+            if eq == nil {
+                out = nil
+            }
+        }
+        return out
+    case *Node, *Edge, Trail, []byte:
+        return cypherKey(a) == cypherKey(b)
+    }
+
+    c, ok := cypherCompare(a, b)
+    return ok && c == 0
+}
+
+// cypherKey returns a string that is the same for equal values, for
+// grouping and DISTINCT. Integers and floats of the same value share a
+// key.
+func cypherKey(v interface{}) string {
+    switch v := v.(type) {
+    case nil:
+        return "null"
+    case bool:
+        return strconv.FormatBool(v)
+    case int64:
+        return "#" + strconv.FormatInt(v, 10)
+    case float64:
+        // whole floats share the key of the equal integer
+        if v == math.Trunc(v) && v >= -(1<<63) && v < 1<<63 {
+            return "#" + strconv.FormatInt(int64(v), 10)
+        }
+        return "#" + strconv.FormatFloat(v, 'g', -1, 64)
+    case string:
+        return strconv.Quote(v)
+    case time.Time:
+        return "@" + v.Format(time.RFC3339Nano)
+    case []byte:
+        return "b" + strconv.Quote(string(v))
+    case []interface{}:
+        parts := make([]string, len(v))
+        for i, item := range v {
+            parts[i] = cypherKey(item)
+        }
+        return "[" + strings.Join(parts, ", ") + "]"
+    case *Node:
+        return fmt.Sprintf("node %p", v)
+    case *Edge:
+        return fmt.Sprintf("edge %p", v)
+    case Trail:
+        parts := make([]string, 0, len(v.Nodes)+len(v.Edges))
+        for i, n := range v.Nodes {
+            parts = append(parts, cypherKey(n))
+            if i < len(v.Edges) {
+                parts = append(parts, cypherKey(v.Edges[i]))
+            }
+        }
+        return "path(" + strings.Join(parts, ", ") + ")"
+    }
+    return "?"
+}
+
+// cypherOrderOf orders any two values for ORDER BY, min and max: values
+// that compare are ordered, others by type, and null comes last.
+func cypherOrderOf(a interface{}, b interface{}) int {
+
+    if c, ok := cypherCompare(a, b); ok {
+        return c
+    }
+
+    rank := func(v interface{}) int {
+        switch v.(type) {
+        case *Node:
+            return 0
+        case *Edge:
+            return 1
+        case []interface{}:
+            return 2
+        case Trail:
+            return 3
+        case string:
+            return 4
+        case bool:
+            return 5
+        case int64, float64:
+            return 6
+        case time.Time:
+            return 7
+        case []byte:
+            return 8
+        case nil:
+            return 10
+        }
+        return 9
+    }
+
+    ra, rb := rank(a), rank(b)
+    switch {
+    case ra < rb:
+        return -1
+    case ra > rb:
+        return 1
+    }
+    if ka, kb := cypherKey(a), cypherKey(b); ka != kb {
+        if ka < kb {
+            return -1
+        }
+        return 1
+    }
+    return 0
+}
+
+// Matching
+
+// errQueryDone stops matching once enough rows are found.
+var errQueryDone = errors.New("Query done")
+
+// cypherMatcher finds the bindings of patterns in a graph.
+type cypherMatcher struct {
+    g    *Graph
+    idx  map[*Node]int
+}
+
+// cypherBind returns a copy of vars with name bound to v.
+func cypherBind(vars map[string]interface{}, name string, v interface{}) map[string]interface{} {
+
+    if len(name) == 0 {
+        return vars
+    }
+
+    out := make(map[string]interface{}, len(vars)+1)
+    for key, value := range vars {
+        out[key] = value
+    }
+    out[name] = v
+    return out
+}
+
+// propsMatch returns true if every pattern property equals the property
+// of the node or edge.
+func (m *cypherMatcher) propsMatch(props []cypherProperty, of interface{}, vars map[string]interface{}) (bool, error) {
+
+    env := &cypherEnv{vars: vars}
+    for _, prop := range props {
+        want, err := prop.value.eval(env)
+        if err != nil {
+            return false, err
+        }
+        got, err := (&cypherLookup{x: &cypherLiteral{of}, key: prop.key}).eval(env)
+        if err != nil {
+            return false, err
+        }
+        if cypherEqual(got, want) != true {
+            return false, nil
+        }
+    }
+
+    return true, nil
+}
+
+// bindNode matches n against a node pattern and returns vars with the
+// node bound to its variable.
+func (m *cypherMatcher) bindNode(p cypherNode, n *Node, vars map[string]interface{}) (map[string]interface{}, bool, error) {
+
+    if _, ok := m.idx[n]; !ok {
+        return nil, false, nil
+    }
+    if bound, ok := vars[p.name]; ok && len(p.name) > 0 {
+        other, isNode := bound.(*Node)
+        if !isNode {
+            return nil, false, errors.New("Variable " + p.name + " is not a node")
+        }
+        if other != n {
+            return nil, false, nil
+        }
+    }
+    for _, label := range p.labels {
+        if !n.HasLabel(label) {
+            return nil, false, nil
+        }
+    }
+    if ok, err := m.propsMatch(p.props, n, vars); !ok || err != nil {
+        return nil, false, err
+    }
+
+    return cypherBind(vars, p.name, n), true, nil
+}
+
+// edgeMatches returns true if e can be bound to a fixed length edge
+// pattern.
+func (m *cypherMatcher) edgeMatches(p cypherEdge, e *Edge, vars map[string]interface{}) (bool, error) {
+
+    if bound, ok := vars[p.name]; ok && len(p.name) > 0 {
+        other, isEdge := bound.(*Edge)
+        if !isEdge {
+            return false, errors.New("Variable " + p.name + " is not an edge")
+        }
+        if other != e {
+            return false, nil
+        }
+    }
+    return m.propsMatch(p.props, e, vars)
+}
+
+// candidates returns the nodes a pattern can start from, using the label
+// index when the node has a label.
+func (m *cypherMatcher) candidates(p cypherNode, vars map[string]interface{}) []*Node {
+
+    if n, ok := vars[p.name].(*Node); ok && len(p.name) > 0 {
+        return []*Node{n}
+    }
+    if len(p.labels) > 0 {
+        return m.g.NodesByLabel(p.labels[0])
+    }
+    return append([]*Node{}, m.g.nodes...)
+}
+
+// matchAll calls emit with every binding of vars that matches all the
+// patterns, using no edge in used.
+func (m *cypherMatcher) matchAll(patterns []cypherPattern, vars map[string]interface{}, used []*Edge, emit func(map[string]interface{}) error) error {
+
+    if len(patterns) == 0 {
+        return emit(vars)
+    }
+
+    p := patterns[0]
+    for _, n := range m.candidates(p.nodes[0], vars) {
+        v, ok, err := m.bindNode(p.nodes[0], n, vars)
+        if err != nil {
+            return err
+        }
+        if !ok {
+            continue
+        }
+        trail := Trail{Nodes: []*Node{n}, Edges: []*Edge{}}
+        err = m.extend(p, 0, v, used, trail, func(v map[string]interface{}, used []*Edge) error {
+            return m.matchAll(patterns[1:], v, used, emit)
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// extend matches the edges of a pattern from its i-th node, the last node
+// of trail, onwards.
+func (m *cypherMatcher) extend(p cypherPattern, i int, vars map[string]interface{}, used []*Edge, trail Trail, emit func(map[string]interface{}, []*Edge) error) error {
+
+    if i == len(p.edges) {
+        if len(p.path) > 0 {
+            trail.Nodes = append([]*Node{}, trail.Nodes...)
+            trail.Edges = append([]*Edge{}, trail.Edges...)
+            vars = cypherBind(vars, p.path, trail)
+        }
+        return emit(vars, used)
+    }
+
+    pe := p.edges[i]
+    filter := TraversalFilter{EdgeTypes: pe.types, Direction: pe.direction}
+    step := func(n *Node, e *Edge, trail Trail) Trail {
+        trail.Distance += e.Distance
+        trail.Edges = append(trail.Edges[:len(trail.Edges):len(trail.Edges)], e)
+        trail.Nodes = append(trail.Nodes[:len(trail.Nodes):len(trail.Nodes)], n)
+        return trail
+    }
+    from := trail.Nodes[len(trail.Nodes)-1]
+
+    if !pe.variable {
+        for _, e := range filter.steps(from) {
+            if containsEdge(used, e) {
+                continue
+            }
+            if ok, err := m.edgeMatches(pe, e, vars); !ok || err != nil {
+                if err != nil {
+                    return err
+                }
+                continue
+            }
+            to := otherEnd(e, from)
+            v, ok, err := m.bindNode(p.nodes[i+1], to, vars)
+            if err != nil {
+                return err
+            }
+            if !ok {
+                continue
+            }
+            v = cypherBind(v, pe.name, e)
+            next := append(used[:len(used):len(used)], e)
+            if err := m.extend(p, i+1, v, next, step(to, e, trail), emit); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    if _, ok := vars[pe.name]; ok && len(pe.name) > 0 {
+        return errors.New("Variable " + pe.name + " is already bound")
+    }
+
+    // walk every sequence of unused edges from the node
+    start := len(trail.Edges)
+    var walk func(n *Node, trail Trail) error
+    walk = func(n *Node, trail Trail) error {
+
+        edges := trail.Edges[start:]
+        if len(edges) >= pe.min {
+            v, ok, err := m.bindNode(p.nodes[i+1], n, vars)
+            if err != nil {
+                return err
+            }
+            if ok {
+                list := make([]interface{}, len(edges))
+                for k, e := range edges {
+                    list[k] = e
+                }
+                v = cypherBind(v, pe.name, list)
+                next := append(used[:len(used):len(used)], edges...)
+                if err := m.extend(p, i+1, v, next, trail, emit); err != nil {
+                    return err
+                }
+            }
+        }
+        if pe.max >= 0 && len(edges) >= pe.max {
+            return nil
+        }
+
+        for _, e := range filter.steps(n) {
+            if containsEdge(used, e) || containsEdge(edges, e) {
+                continue
+            }
+            if ok, err := m.propsMatch(pe.props, e, vars); !ok || err != nil {
+                if err != nil {
+                    return err
+                }
+                continue
+            }
+            to := otherEnd(e, n)
+            if _, ok := m.idx[to]; !ok {
+                continue
+            }
+            if err := walk(to, step(to, e, trail)); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    return walk(from, trail)
+}
+
+// Execution
+
+// cypherRow is a projected row and the variables its ORDER BY sees.
+type cypherRow struct {
+    values  []interface{}
+    env     *cypherEnv
+}
+
+// Execute runs a parsed query on the graph.
+func (g *Graph) Execute(q *Query) (*QueryResult, error) {
+
+    if g == nil {
+        return nil, errors.New("Graph is empty or nil")
+    }
+    if q == nil {
+        return nil, errors.New("Query is nil")
+    }
+
+    res := &QueryResult{Columns: make([]string, len(q.items)), Rows: make([][]interface{}, 0)}
+    aggregate := false
+    for i, item := range q.items {
+        res.Columns[i] = item.name
+        aggregate = aggregate || item.aggregate
+    }
+
+    // without sorting, grouping or DISTINCT matching can stop early
+    stream := !aggregate && !q.distinct && len(q.order) == 0 && q.limit >= 0
+    matched := make([]*cypherEnv, 0)
+    rows := make([]cypherRow, 0)
+
+    collect := func(vars map[string]interface{}) error {
+        env := &cypherEnv{vars: vars}
+        if aggregate {
+            matched = append(matched, env)
+            return nil
+        }
+        row, err := q.project(env)
+        if err != nil {
+            return err
+        }
+        rows = append(rows, row)
+        if stream && len(rows) >= q.skip+q.limit {
+            return errQueryDone
+        }
+        return nil
+    }
+
+    m := &cypherMatcher{g: g, idx: g.nodeIndexes()}
+    var run func(i int, vars map[string]interface{}) error
+    run = func(i int, vars map[string]interface{}) error {
+        if i == len(q.matches) {
+            return collect(vars)
+        }
+        clause := q.matches[i]
+        return m.matchAll(clause.patterns, vars, nil, func(v map[string]interface{}) error {
+            if clause.where != nil {
+                ok, err := clause.where.eval(&cypherEnv{vars: v})
+                if err != nil {
+                    return err
+                }
+                if _, isBool := ok.(bool); ok != nil && !isBool {
+                    return errors.New("WHERE needs a boolean, not " + cypherTypeName(ok))
+                }
+                if ok != true {
+                    return nil
+                }
+            }
+            return run(i+1, v)
+        })
+    }
+
+    if err := run(0, make(map[string]interface{})); err != nil && err != errQueryDone {
+        return nil, errors.New("Query: " + err.Error())
+    }
+
+    if aggregate {
+        var err error
+        if rows, err = q.group(matched); err != nil {
+            return nil, errors.New("Query: " + err.Error())
+        }
+    }
+
+    if q.distinct {
+        seen := make(map[string]bool)
+        unique := rows[:0]
+        for _, row := range rows {
+            key := cypherKey(row.values)
+            if !seen[key] {
+                seen[key] = true
+                unique = append(unique, row)
+            }
+        }
+        rows = unique
+    }
+
+    if len(q.order) > 0 {
+        if err := q.sort(rows); err != nil {
+            return nil, errors.New("Query: " + err.Error())
+        }
+    }
+
+    if q.skip >= len(rows) {
+        rows = rows[:0]
+    } else {
+        rows = rows[q.skip:]
+    }
+    if q.limit >= 0 && q.limit < len(rows) {
+        rows = rows[:q.limit]
+    }
+
+    for _, row := range rows {
+        res.Rows = append(res.Rows, row.values)
+    }
+    return res, nil
+}
+
+// project evaluates the returned items for a row. ORDER BY sees the
+// row variables and the item names.
+func (q *Query) project(env *cypherEnv) (cypherRow, error) {
+
+    values := make([]interface{}, len(q.items))
+    for i, item := range q.items {
+        v, err := item.expr.eval(env)
+        if err != nil {
+            return cypherRow{}, err
+        }
+        values[i] = v
+    }
+
+    vars := env.vars
+    for i, item := range q.items {
+        vars = cypherBind(vars, item.name, values[i])
+    }
+    return cypherRow{values: values, env: &cypherEnv{vars: vars, group: env.group}}, nil
+}
+
+// group aggregates the matched rows, grouping by the items that do not
+// aggregate.
+func (q *Query) group(matched []*cypherEnv) ([]cypherRow, error) {
+
+    groups := make([][]*cypherEnv, 0)
+    index := make(map[string]int)
+
+    for _, env := range matched {
+        keys := make([]interface{}, 0)
+        for _, item := range q.items {
+            if item.aggregate {
+                continue
+            }
+            v, err := item.expr.eval(env)
+            if err != nil {
+                return nil, err
+            }
+            keys = append(keys, v)
+        }
+        key := cypherKey(keys)
+        i, ok := index[key]
+        if !ok {
+            i = len(groups)
+            index[key] = i
+            groups = append(groups, nil)
+        }
+        groups[i] = append(groups[i], env)
+    }
+
+    // aggregating nothing by nothing still gives one row
+    grouped := false
+    for _, item := range q.items {
+        grouped = grouped || !item.aggregate
+    }
+    if len(groups) == 0 && !grouped {
+        groups = append(groups, []*cypherEnv{})
+    }
+
+    rows := make([]cypherRow, 0, len(groups))
+    for _, group := range groups {
+        vars := make(map[string]interface{})
+        if len(group) > 0 {
+            vars = group[0].vars
+        }
+        row, err := q.project(&cypherEnv{vars: vars, group: group})
+        if err != nil {
+            return nil, err
+        }
+        rows = append(rows, row)
+    }
+
+    return rows, nil
+}
+
+// sort orders rows by the ORDER BY expressions.
+func (q *Query) sort(rows []cypherRow) error {
+
+    keys := make([][]interface{}, len(rows))
+    for i, row := range rows {
+        keys[i] = make([]interface{}, len(q.order))
+        for j, order := range q.order {
+            v, err := order.expr.eval(row.env)
+            if err != nil {
+                return err
+            }
+            keys[i][j] = v
+        }
+    }
+
+    perm := make([]int, len(rows))
+    for i := range perm {
+        perm[i] = i
+    }
+    sort.SliceStable(perm, func(a, b int) bool {
+        for j, order := range q.order {
+            c := cypherOrderOf(keys[perm[a]][j], keys[perm[b]][j])
+            if c == 0 {
+                continue
+            }
+            if order.desc {
+                c = -c
+            }
+            return c < 0
+        }
+        return false
+    })
+
+    sorted := make([]cypherRow, len(rows))
+    for i, p := range perm {
+        sorted[i] = rows[p]
+    }
+    copy(rows, sorted)
+    return nil
+}
@@ -0,0 +1,176 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+// queryGraph is a chain of people A, B and C who know the next one, and
+// a robot D known by C and without an age. A also likes C.
+func queryGraph(t *testing.T) *Graph {
+
+    g := NewGraph("query")
+    a, _ := g.AddNode("a", "A", "Person")
+    b, _ := g.AddNode("b", "B", "Person")
+    c, _ := g.AddNode("c", "C", "Person")
+    d, _ := g.AddNode("d", "D", "Robot")
+    a.SetInt("age", 30)
+    b.SetInt("age", 25)
+    c.SetInt("age", 35)
+
+    for _, err := range []error{
+        g.AddEdge("e1", "KNOWS", 1, a, b),
+        g.AddEdge("e2", "KNOWS", 1, b, c),
+        g.AddEdge("e3", "KNOWS", 1, c, d),
+        g.AddEdge("e4", "LIKES", 1, a, c),
+    } {
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return g
+}
+
+func TestQuery(t *testing.T) {
+
+    g := queryGraph(t)
+    tests := []struct {
+        query  string
+        want   string
+    }{
+        // ORDER BY, with null last and first when descending
+        {"MATCH (n:Person) RETURN n.name ORDER BY n.age", "[[B] [A] [C]]"},
+        {"MATCH (n:Person) RETURN n.name, n.age ORDER BY n.age DESC", "[[C 35] [A 30] [B 25]]"},
+        {"MATCH (n) RETURN n.name ORDER BY n.age", "[[B] [A] [C] [D]]"},
+        {"MATCH (n) RETURN n.name ORDER BY n.age DESC", "[[D] [C] [A] [B]]"},
+        {"MATCH (n) RETURN n.name AS name ORDER BY name DESC", "[[D] [C] [B] [A]]"},
+
+        // SKIP and LIMIT
+        {"MATCH (n) RETURN n.name ORDER BY n.name SKIP 1 LIMIT 2", "[[B] [C]]"},
+        {"MATCH (n) RETURN n.name ORDER BY n.name LIMIT 0", "[]"},
+        {"MATCH (n) RETURN n.name SKIP 10", "[]"},
+
+        // aggregates, grouped by the other returned values
+        {"MATCH (n) RETURN count(*), count(n.age), sum(n.age), avg(n.age), min(n.age), max(n.age)",
+            "[[4 3 90 30 25 35]]"},
+        {"MATCH (n) RETURN labels(n)[0] AS label, count(*), collect(n.name) ORDER BY label",
+            "[[Person 3 [A B C]] [Robot 1 [D]]]"},
+        {"MATCH (x)-[:KNOWS]->(y) RETURN count(DISTINCT x.name), max(y.name)", "[[3 D]]"},
+        {"MATCH (n:Nobody) RETURN count(*)", "[[0]]"},
+
+        // variable length edges and paths
+        {"MATCH ({name: 'A'})-[:KNOWS*1..3]->(x) RETURN x.name ORDER BY x.name", "[[B] [C] [D]]"},
+        {"MATCH ({name: 'A'})-[:KNOWS*..2]->(x) RETURN x.name ORDER BY x.name", "[[B] [C]]"},
+        {"MATCH p = ({name: 'A'})-[:KNOWS*2]->(x) RETURN x.name, length(p)", "[[C 2]]"},
+        {"MATCH ({name: 'A'})-[*]->(x) RETURN DISTINCT x.name ORDER BY x.name", "[[B] [C] [D]]"},
+        {"MATCH ({name: 'D'})<-[:KNOWS*1..3]-(x) RETURN x.name ORDER BY x.name", "[[A] [B] [C]]"},
+        {"MATCH (x {name: 'A'})-[r:KNOWS|LIKES]->(y) RETURN type(r), y.name ORDER BY y.name",
+            "[[KNOWS B] [LIKES C]]"},
+
+        // null handling
+        {"MATCH (n) WHERE n.age IS NULL RETURN n.name", "[[D]]"},
+        {"MATCH (n) WHERE n.age > 26 RETURN n.name ORDER BY n.name", "[[A] [C]]"},
+        {"MATCH (n) WHERE n.age <> 30 RETURN n.name ORDER BY n.name", "[[B] [C]]"},
+        {"MATCH (n) RETURN n.name, n.missing, coalesce(n.age, 0) ORDER BY n.name",
+            "[[A <nil> 30] [B <nil> 25] [C <nil> 35] [D <nil> 0]]"},
+        {"MATCH (n {name: 'D'}) RETURN n.age + 1, n.age = null, null IS NULL", "[[<nil> <nil> true]]"},
+        {"MATCH (n) RETURN sum(n.missing), avg(n.missing), min(n.missing), collect(n.missing), count(n.missing)",
+            "[[0 <nil> <nil> [] 0]]"},
+
+        // string escapes
+        {`MATCH (n {name: 'A'}) RETURN 'a\u0041', "\U0001F600", 'it\'s', "\"\\"`, `[[aA 😀 it's "\]]`},
+    }
+
+    for _, test := range tests {
+        res, err := g.Query(test.query)
+        if err != nil {
+            t.Errorf("%s: %v", test.query, err)
+            continue
+        }
+        if got := fmt.Sprint(res.Rows); got != test.want {
+            t.Errorf("%s: got %s, want %s", test.query, got, test.want)
+        }
+    }
+}
+
+func TestQueryResultTypes(t *testing.T) {
+
+    res, err := queryGraph(t).Query("MATCH (n) RETURN count(*) AS c, avg(n.age), sum(n.age), n.age > 1 AS old LIMIT 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if got := strings.Join(res.Columns, ","); got != "c,avg(n.age),sum(n.age),old" {
+        t.Errorf("columns %s", got)
+    }
+    if got := fmt.Sprintf("%T %T %T", res.Rows[0][0], res.Rows[0][1], res.Rows[0][2]); got != "int64 float64 int64" {
+        t.Errorf("cell types %s, want int64 float64 int64", got)
+    }
+}
+
+func TestQueryErrors(t *testing.T) {
+
+    tests := []struct {
+        query  string
+        want   string
+    }{
+        {"MATCH (n RETURN n", `Query line 1: Expected ")" at "RETURN"`},
+        {"MATCH (n)\nRETURN", "Query line 2: Unexpected end of query"},
+        {"MATCH (n) RETURN n.name LIMIT -1", `Query line 1: Expected a whole number at "-"`},
+        {"RETURN 1", `Query line 1: Expected MATCH at "RETURN"`},
+        {"MATCH (n) RETURN n.name ORDER BY", "Query line 1: Unexpected end of query"},
+        {"MATCH (n)-[:KNOWS*3..1]->(m) RETURN m", "Query line 1: Edge length range is empty"},
+        {"MATCH (n) RETURN 'abc", "Query: Unterminated string"},
+        {`MATCH (n) RETURN 'a\q'`, `Query: Invalid escape \q`},
+        {`MATCH (n) RETURN 'a\u00G1'`, `Query: Invalid escape \u00G1`},
+        {`MATCH (n) RETURN 'a\uD800'`, `Query: Invalid escape \uD800`},
+    }
+
+    for _, test := range tests {
+        _, err := ParseQuery(test.query)
+        if err == nil || err.Error() != test.want {
+            t.Errorf("%q: got error %v, want %s", test.query, err, test.want)
+        }
+    }
+
+    if _, err := queryGraph(t).Query("MATCH (n) RETURN m.name"); err == nil || err.Error() != "Query: Unknown variable m" {
+        t.Errorf("unknown variable: got error %v", err)
+    }
+}
+
+func TestQueryDistinctIntegers(t *testing.T) {
+
+    g := NewGraph("numbers")
+    for i, v := range []int64{9007199254740992, 9007199254740993, 9007199254740993} {
+        n, _ := g.AddNode(fmt.Sprint(i), fmt.Sprint(i))
+        n.SetInt("v", v)
+    }
+
+    tests := []struct {
+        query  string
+        want   string
+    }{
+        {"MATCH (n) RETURN DISTINCT n.v ORDER BY n.v", "[[9007199254740992] [9007199254740993]]"},
+        {"MATCH (n) RETURN count(DISTINCT n.v)", "[[2]]"},
+        {"MATCH (n) RETURN n.v, count(*) ORDER BY n.v", "[[9007199254740992 1] [9007199254740993 2]]"},
+        {"MATCH (n) RETURN count(DISTINCT [1, 1.0, 1.5][toInteger(n.name)])", "[[2]]"},
+    }
+
+    for _, test := range tests {
+        res, err := g.Query(test.query)
+        if err != nil {
+            t.Errorf("%s: %v", test.query, err)
+            continue
+        }
+        if got := fmt.Sprint(res.Rows); got != test.want {
+            t.Errorf("%s: got %s, want %s", test.query, got, test.want)
+        }
+    }
+}
@@ -0,0 +1,953 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const (
+    rdfNamespace  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+    xsdNamespace  = "http://www.w3.org/2001/XMLSchema#"
+    rdfBase       = "urn:graph:"
+)
+
+// RDFOptions configures reading and writing RDF.
+type RDFOptions struct {
+    // Base resolves relative IRIs when reading. When writing, node ids,
+    // property keys and edge names that are not IRIs are appended to it,
+    // or to "urn:graph:" if it is empty, and reading removes it again.
+    Base      string
+    // Prefixes maps prefix names to namespace IRIs. They are declared
+    // and used when writing Turtle, expand prefixed names when writing
+    // and are known before any @prefix when reading Turtle.
+    Prefixes  map[string]string
+}
+
+const (
+    rdfIRI = iota
+    rdfBlank
+    rdfLiteral
+)
+
+// rdfTerm is a subject, predicate or object of a triple.
+type rdfTerm struct {
+    kind      int
+    value     string
+    lang      string
+    datatype  string
+}
+
+// rdfLocalName returns the part of an IRI after its last '#', '/' or
+// ':', or the whole IRI if that part is empty.
+func rdfLocalName(iri string) string {
+    i := strings.LastIndexAny(iri, "#/:")
+    if i < 0 || i == len(iri)-1 {
+        return iri
+    }
+    return iri[i+1:]
+}
+
+// rdfLangTag reports whether s is a language tag such as "en" or "en-GB".
+func rdfLangTag(s string) bool {
+    for i, part := range strings.Split(s, "-") {
+        if len(part) == 0 {
+            return false
+        }
+        for _, r := range part {
+            letter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+            if !letter && (i == 0 || r < '0' || r > '9') {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// rdfBuilder turns triples into nodes, edges and properties.
+type rdfBuilder struct {
+    g       *Graph
+    base    string
+    nodes   map[rdfTerm]*Node
+    seen    map[[3]rdfTerm]bool
+    values  map[rdfProperty][]Value
+}
+
+// rdfProperty is a property read from literals.
+type rdfProperty struct {
+    node  *Node
+    key   string
+}
+
+// local removes the base from an IRI, undoing rdfWriter.iri. IRIs that
+// the writer would not have resolved against the base are kept whole.
+func (b *rdfBuilder) local(iri string) string {
+    rest := strings.TrimPrefix(iri, b.base)
+    if len(rest) == len(iri) || len(rest) == 0 || rdfAbsolute(rest) {
+        return iri
+    }
+    return rest
+}
+
+func (b *rdfBuilder) node(t rdfTerm) *Node {
+
+    if n, ok := b.nodes[t]; ok {
+        return n
+    }
+
+    n := NewNode()
+    if t.kind == rdfBlank {
+        n.Properties["id"] = "_:" + t.value
+        n.Properties["name"] = t.value
+    } else {
+        n.Properties["id"] = b.local(t.value)
+        n.Properties["name"] = rdfLocalName(t.value)
+    }
+    b.nodes[t] = n
+    b.g.insertNode(n)
+    return n
+}
+
+func (b *rdfBuilder) triple(s rdfTerm, p rdfTerm, o rdfTerm) error {
+
+    key := [3]rdfTerm{s, p, o}
+    if b.seen[key] {
+        return nil
+    }
+    b.seen[key] = true
+
+    subject := b.node(s)
+    if p.value == rdfNamespace+"type" && o.kind == rdfIRI {
+        subject.addLabel(b.local(o.value))
+        return nil
+    }
+    if o.kind == rdfLiteral {
+        name := b.local(p.value)
+        if len(o.lang) > 0 {
+            name += "@" + o.lang
+        }
+        // repeated predicates keep every literal as a list
+        p := rdfProperty{subject, name}
+        b.values[p] = append(b.values[p], StringValue(o.value))
+        if len(b.values[p]) == 1 {
+            subject.Properties[name] = o.value
+            return nil
+        }
+        return subject.SetList(name, b.values[p]...)
+    }
+
+    e := NewEdge()
+    e.Properties["name"] = b.local(p.value)
+    e.kind = e.Properties["name"]
+    return e.Link(subject, b.node(o))
+}
+
+// ReadNTriples reads RDF N-Triples. Subjects and objects that are IRIs
+// or blank nodes become nodes, with the IRI, or "_:" and the blank node
+// label, as the "id" property and its local name as the "name" property.
+// An rdf:type IRI becomes a label of the subject, other triples with
+// such objects become edges named by the predicate IRI, and triples
+// with literal objects become properties of the subject keyed by the
+// predicate IRI, followed by "@" and the language tag for tagged
+// literals. The base of opts is removed from ids, names and keys
+// as the writers add it, so a "name" property replaces the local name.
+// A predicate with several literals for a subject gives a list of them
+// in the order read, see GetList. Datatypes are not kept and repeated
+// triples are read once.
+func ReadNTriples(r io.Reader, opts RDFOptions) (*Graph, error) {
+    return ReadTurtle(r, opts)
+}
+
+// ReadTurtle reads RDF Turtle, which includes N-Triples, mapping it to a
+// graph as ReadNTriples does.
+func ReadTurtle(r io.Reader, opts RDFOptions) (*Graph, error) {
+
+    src, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    p := &turtleParser{
+        src:       string(src),
+        line:      1,
+        base:      opts.Base,
+        prefixes:  make(map[string]string),
+        b:         &rdfBuilder{
+            g:      NewGraph(""),
+            base:   rdfBase,
+            nodes:  make(map[rdfTerm]*Node),
+            seen:   make(map[[3]rdfTerm]bool),
+            values: make(map[rdfProperty][]Value),
+        },
+    }
+    if len(opts.Base) > 0 {
+        p.b.base = opts.Base
+    }
+    for prefix, ns := range opts.Prefixes {
+        p.prefixes[prefix] = ns
+    }
+
+    if err := p.parse(); err != nil {
+        return nil, errors.New("Turtle line " + strconv.Itoa(p.line) + ": " + err.Error())
+    }
+
+    return p.b.g, nil
+}
+
+// turtleParser is a recursive descent parser working on the source
+// directly.
+type turtleParser struct {
+    src       string
+    pos       int
+    line      int
+    base      string
+    prefixes  map[string]string
+    blanks    int
+    b         *rdfBuilder
+}
+
+// skip moves past white space and comments.
+func (p *turtleParser) skip() {
+    for p.pos < len(p.src) {
+        switch c := p.src[p.pos]; {
+        case c == '\n':
+            p.line++
+            p.pos++
+        case c == ' ' || c == '\t' || c == '\r':
+            p.pos++
+        case c == '#':
+            for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+                p.pos++
+            }
+        default:
+            return
+        }
+    }
+}
+
+func (p *turtleParser) peek() byte {
+    p.skip()
+    if p.pos >= len(p.src) {
+        return 0
+    }
+    return p.src[p.pos]
+}
+
+func (p *turtleParser) expect(c byte) error {
+    if p.peek() != c {
+        return errors.New("Expected " + strconv.Quote(string(c)))
+    }
+    p.pos++
+    return nil
+}
+
+// keyword matches a case insensitive word not followed by a name
+// character.
+func (p *turtleParser) keyword(word string) bool {
+    p.skip()
+    end := p.pos + len(word)
+    if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+        return false
+    }
+    if end < len(p.src) && turtleNameChar(p.src[end]) {
+        return false
+    }
+    p.pos = end
+    return true
+}
+
+func turtleNameChar(c byte) bool {
+    return c == '_' || c == '-' || c == ':' || c >= 0x80 ||
+        (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *turtleParser) parse() error {
+
+    for p.peek() != 0 {
+        switch {
+        case p.keyword("@prefix"):
+            if err := p.prefix(); err != nil {
+                return err
+            }
+            if err := p.expect('.'); err != nil {
+                return err
+            }
+        case p.keyword("@base"):
+            if err := p.baseIRI(); err != nil {
+                return err
+            }
+            if err := p.expect('.'); err != nil {
+                return err
+            }
+        case p.keyword("prefix"):
+            if err := p.prefix(); err != nil {
+                return err
+            }
+        case p.keyword("base"):
+            if err := p.baseIRI(); err != nil {
+                return err
+            }
+        default:
+            if err := p.triples(); err != nil {
+                return err
+            }
+            if err := p.expect('.'); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
+func (p *turtleParser) prefix() error {
+
+    p.skip()
+    colon := strings.IndexByte(p.src[p.pos:], ':')
+    if colon < 0 {
+        return errors.New("Expected prefix name")
+    }
+    name := p.src[p.pos : p.pos+colon]
+    p.pos += colon + 1
+
+    if p.peek() != '<' {
+        return errors.New("Expected namespace IRI")
+    }
+    iri, err := p.iriRef()
+    if err != nil {
+        return err
+    }
+    p.prefixes[name] = iri
+    return nil
+}
+
+func (p *turtleParser) baseIRI() error {
+    if p.peek() != '<' {
+        return errors.New("Expected base IRI")
+    }
+    iri, err := p.iriRef()
+    if err != nil {
+        return err
+    }
+    p.base = iri
+    return nil
+}
+
+func (p *turtleParser) triples() error {
+
+    if p.peek() == '[' {
+        subject, err := p.blankNodePropertyList()
+        if err != nil {
+            return err
+        }
+        if c := p.peek(); c == '.' {
+            return nil
+        }
+        return p.predicateObjectList(subject)
+    }
+
+    subject, err := p.term()
+    if err != nil {
+        return err
+    }
+    if subject.kind == rdfLiteral {
+        return errors.New("Literal used as subject")
+    }
+    return p.predicateObjectList(subject)
+}
+
+func (p *turtleParser) predicateObjectList(subject rdfTerm) error {
+
+    for {
+        var predicate rdfTerm
+        if p.keyword("a") {
+            predicate = rdfTerm{kind: rdfIRI, value: rdfNamespace + "type"}
+        } else {
+            var err error
+            predicate, err = p.term()
+            if err != nil {
+                return err
+            }
+            if predicate.kind != rdfIRI {
+                return errors.New("Predicate must be an IRI")
+            }
+        }
+
+        for {
+            object, err := p.object()
+            if err != nil {
+                return err
+            }
+            if err := p.b.triple(subject, predicate, object); err != nil {
+                return err
+            }
+            if p.peek() != ',' {
+                break
+            }
+            p.pos++
+        }
+
+        if p.peek() != ';' {
+            return nil
+        }
+        for p.peek() == ';' {
+            p.pos++
+        }
+        if c := p.peek(); c == '.' || c == ']' || c == 0 {
+            return nil
+        }
+    }
+}
+
+func (p *turtleParser) object() (rdfTerm, error) {
+    switch p.peek() {
+    case '[':
+        return p.blankNodePropertyList()
+    case '(':
+        return p.collection()
+    }
+    return p.term()
+}
+
+func (p *turtleParser) newBlank() rdfTerm {
+    p.blanks++
+    return rdfTerm{kind: rdfBlank, value: "genid" + strconv.Itoa(p.blanks)}
+}
+
+func (p *turtleParser) blankNodePropertyList() (rdfTerm, error) {
+
+    p.pos++
+    node := p.newBlank()
+    if p.peek() == ']' {
+        p.pos++
+        return node, nil
+    }
+    if err := p.predicateObjectList(node); err != nil {
+        return node, err
+    }
+    return node, p.expect(']')
+}
+
+// collection builds an rdf:first and rdf:rest list.
+func (p *turtleParser) collection() (rdfTerm, error) {
+
+    p.pos++
+    nilTerm := rdfTerm{kind: rdfIRI, value: rdfNamespace + "nil"}
+    first := rdfTerm{kind: rdfIRI, value: rdfNamespace + "first"}
+    rest := rdfTerm{kind: rdfIRI, value: rdfNamespace + "rest"}
+
+    head := nilTerm
+    var last rdfTerm
+    for p.peek() != ')' {
+        if p.peek() == 0 {
+            return head, errors.New("Unterminated collection")
+        }
+        item, err := p.object()
+        if err != nil {
+            return head, err
+        }
+        cell := p.newBlank()
+        if head == nilTerm {
+            head = cell
+        } else if err := p.b.triple(last, rest, cell); err != nil {
+            return head, err
+        }
+        if err := p.b.triple(cell, first, item); err != nil {
+            return head, err
+        }
+        last = cell
+    }
+    p.pos++
+
+    if head != nilTerm {
+        if err := p.b.triple(last, rest, nilTerm); err != nil {
+            return head, err
+        }
+    }
+    return head, nil
+}
+
+// term reads an IRI, prefixed name, blank node label or literal.
+func (p *turtleParser) term() (rdfTerm, error) {
+
+    c := p.peek()
+    switch {
+    case c == 0:
+        return rdfTerm{}, errors.New("Unexpected end of input")
+    case c == '<':
+        iri, err := p.iriRef()
+        return rdfTerm{kind: rdfIRI, value: iri}, err
+    case c == '"' || c == '\'':
+        return p.literal()
+    case c == '_' && strings.HasPrefix(p.src[p.pos:], "_:"):
+        p.pos += 2
+        return rdfTerm{kind: rdfBlank, value: p.name()}, nil
+    case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+        return p.number()
+    case p.keyword("true"):
+        return rdfTerm{kind: rdfLiteral, value: "true", datatype: xsdNamespace + "boolean"}, nil
+    case p.keyword("false"):
+        return rdfTerm{kind: rdfLiteral, value: "false", datatype: xsdNamespace + "boolean"}, nil
+    }
+
+    name := p.name()
+    colon := strings.IndexByte(name, ':')
+    if colon < 0 {
+        return rdfTerm{}, errors.New("Unexpected " + strconv.Quote(string(c)))
+    }
+    ns, ok := p.prefixes[name[:colon]]
+    if !ok {
+        return rdfTerm{}, errors.New("Unknown prefix " + strconv.Quote(name[:colon]))
+    }
+    local := name[colon+1:]
+    for _, esc := range "~.-!$&'()*+,;=/?#@_%" {
+        local = strings.Replace(local, "\\"+string(esc), string(esc), -1)
+    }
+    return rdfTerm{kind: rdfIRI, value: ns + local}, nil
+}
+
+// name reads a prefixed name or blank node label. A final '.' ends the
+// statement rather than the name.
+func (p *turtleParser) name() string {
+
+    start := p.pos
+    for p.pos < len(p.src) {
+        c := p.src[p.pos]
+        if c == '\\' && p.pos+1 < len(p.src) {
+            p.pos += 2
+            continue
+        }
+        if !turtleNameChar(c) && c != '.' && c != '%' {
+            break
+        }
+        p.pos++
+    }
+    for p.pos > start && p.src[p.pos-1] == '.' {
+        p.pos--
+    }
+    return p.src[start:p.pos]
+}
+
+func (p *turtleParser) iriRef() (string, error) {
+
+    end := strings.IndexByte(p.src[p.pos:], '>')
+    if end < 0 {
+        return "", errors.New("Unterminated IRI")
+    }
+    iri, err := rdfUnescape(p.src[p.pos+1 : p.pos+end])
+    if err != nil {
+        return "", err
+    }
+    p.pos += end + 1
+
+    if len(p.base) > 0 {
+        base, err := url.Parse(p.base)
+        ref, err2 := url.Parse(iri)
+        if err == nil && err2 == nil {
+            iri = base.ResolveReference(ref).String()
+        }
+    }
+    return iri, nil
+}
+
+func (p *turtleParser) number() (rdfTerm, error) {
+
+    start := p.pos
+    if c := p.src[p.pos]; c == '+' || c == '-' {
+        p.pos++
+    }
+    datatype := "integer"
+    for p.pos < len(p.src) {
+        c := p.src[p.pos]
+        switch {
+        case c >= '0' && c <= '9':
+        case c == '.' && datatype == "integer" && p.pos+1 < len(p.src) && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9':
+            datatype = "decimal"
+        case c == 'e' || c == 'E':
+            datatype = "double"
+            if p.pos+1 < len(p.src) && (p.src[p.pos+1] == '+' || p.src[p.pos+1] == '-') {
+                p.pos++
+            }
+        default:
+            goto done
+        }
+        p.pos++
+    }
+done:
+    value := p.src[start:p.pos]
+    if _, err := strconv.ParseFloat(value, 64); err != nil {
+        return rdfTerm{}, errors.New("Invalid number " + strconv.Quote(value))
+    }
+    return rdfTerm{kind: rdfLiteral, value: value, datatype: xsdNamespace + datatype}, nil
+}
+
+func (p *turtleParser) literal() (rdfTerm, error) {
+
+    quote := p.src[p.pos : p.pos+1]
+    if strings.HasPrefix(p.src[p.pos:], quote+quote+quote) {
+        quote += quote + quote
+    }
+    p.pos += len(quote)
+
+    start := p.pos
+    for {
+        if p.pos >= len(p.src) || (len(quote) == 1 && p.src[p.pos] == '\n') {
+            return rdfTerm{}, errors.New("Unterminated string")
+        }
+        if p.src[p.pos] == '\\' {
+            p.pos += 2
+            continue
+        }
+        if strings.HasPrefix(p.src[p.pos:], quote) {
+            break
+        }
+        if p.src[p.pos] == '\n' {
+            p.line++
+        }
+        p.pos++
+    }
+    value, err := rdfUnescape(p.src[start:p.pos])
+    if err != nil {
+        return rdfTerm{}, err
+    }
+    p.pos += len(quote)
+
+    t := rdfTerm{kind: rdfLiteral, value: value}
+    if p.pos < len(p.src) && p.src[p.pos] == '@' {
+        p.pos++
+        start := p.pos
+        for p.pos < len(p.src) && (turtleNameChar(p.src[p.pos]) && p.src[p.pos] != ':') {
+            p.pos++
+        }
+        t.lang = strings.ToLower(p.src[start:p.pos])
+        if !rdfLangTag(t.lang) {
+            return t, errors.New("Invalid language tag")
+        }
+    } else if strings.HasPrefix(p.src[p.pos:], "^^") {
+        p.pos += 2
+        dt, err := p.term()
+        if err != nil {
+            return t, err
+        }
+        if dt.kind != rdfIRI {
+            return t, errors.New("Datatype must be an IRI")
+        }
+        t.datatype = dt.value
+    }
+    return t, nil
+}
+
+// rdfUnescape replaces string and \u escapes.
+func rdfUnescape(s string) (string, error) {
+
+    if strings.IndexByte(s, '\\') < 0 {
+        return s, nil
+    }
+
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        if s[i] != '\\' {
+            b.WriteByte(s[i])
+            continue
+        }
+        if i+1 >= len(s) {
+            return "", errors.New("Invalid escape")
+        }
+        i++
+        switch s[i] {
+        case 't':
+            b.WriteByte('\t')
+        case 'b':
+            b.WriteByte('\b')
+        case 'n':
+            b.WriteByte('\n')
+        case 'r':
+            b.WriteByte('\r')
+        case 'f':
+            b.WriteByte('\f')
+        case '"', '\'', '\\':
+            b.WriteByte(s[i])
+        case 'u', 'U':
+            size := 4
+            if s[i] == 'U' {
+                size = 8
+            }
+            if i+size >= len(s) {
+                return "", errors.New("Invalid escape")
+            }
+            v, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+            if err != nil {
+                return "", errors.New("Invalid escape")
+            }
+            b.WriteRune(rune(v))
+            i += size
+        default:
+            return "", errors.New("Invalid escape")
+        }
+    }
+
+    return b.String(), nil
+}
+
+// rdfWriter maps the graph back to terms.
+type rdfWriter struct {
+    opts     RDFOptions
+    turtle   bool
+    blanks   map[*Node]string
+}
+
+// absolute reports whether s starts with an IRI scheme.
+func rdfAbsolute(s string) bool {
+    colon := strings.IndexByte(s, ':')
+    if colon <= 0 {
+        return false
+    }
+    for i, c := range s[:colon] {
+        letter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+        if !letter && (i == 0 || !((c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.')) {
+            return false
+        }
+    }
+    return true
+}
+
+// iri expands prefixed names and resolves names against the base.
+func (w *rdfWriter) iri(s string) string {
+    if colon := strings.IndexByte(s, ':'); colon >= 0 {
+        if ns, ok := w.opts.Prefixes[s[:colon]]; ok {
+            return ns + s[colon+1:]
+        }
+    }
+    if rdfAbsolute(s) {
+        return s
+    }
+    if len(w.opts.Base) > 0 {
+        return w.opts.Base + s
+    }
+    return rdfBase + s
+}
+
+// iriTerm writes an IRI, as a prefixed name in Turtle where possible.
+func (w *rdfWriter) iriTerm(iri string) string {
+
+    if w.turtle {
+        if iri == rdfNamespace+"type" {
+            return "a"
+        }
+        best := ""
+        for prefix, ns := range w.opts.Prefixes {
+            local := strings.TrimPrefix(iri, ns)
+            if len(ns) > 0 && len(local) < len(iri) && turtleLocal(local) && (len(best) == 0 || len(prefix)+len(local) < len(best)) {
+                best = prefix + ":" + local
+            }
+        }
+        if len(best) > 0 {
+            return best
+        }
+    }
+
+    return rdfIRITerm(iri)
+}
+
+// rdfIRITerm writes an IRI in full, escaping characters IRIs may not
+// contain.
+func rdfIRITerm(iri string) string {
+
+    var b strings.Builder
+    b.WriteByte('<')
+    for _, r := range iri {
+        if r <= ' ' || strings.ContainsRune("<>\"{}|^`\\", r) {
+            b.WriteString("\\u" + strconv.FormatInt(int64(r)+0x10000, 16)[1:])
+        } else {
+            b.WriteRune(r)
+        }
+    }
+    b.WriteByte('>')
+    return b.String()
+}
+
+// turtleLocal reports whether s can be written as the local part of a
+// prefixed name without escapes.
+func turtleLocal(s string) bool {
+    for i, c := range s {
+        letter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+        if !letter && (i == 0 || !((c >= '0' && c <= '9') || c == '-')) {
+            return false
+        }
+    }
+    return true
+}
+
+// subject writes the term of a node, a blank node if its id is missing
+// or starts with "_:".
+func (w *rdfWriter) subject(n *Node) string {
+    if label, ok := w.blanks[n]; ok {
+        return "_:" + label
+    }
+    return w.iriTerm(w.iri(n.GetProperty("id")))
+}
+
+func rdfLiteralTerm(value string, lang string) string {
+
+    var b strings.Builder
+    b.WriteByte('"')
+    for _, r := range value {
+        switch r {
+        case '"':
+            b.WriteString("\\\"")
+        case '\\':
+            b.WriteString("\\\\")
+        case '\n':
+            b.WriteString("\\n")
+        case '\r':
+            b.WriteString("\\r")
+        case '\t':
+            b.WriteString("\\t")
+        default:
+            b.WriteRune(r)
+        }
+    }
+    b.WriteByte('"')
+    if len(lang) > 0 {
+        b.WriteString("@" + lang)
+    }
+    return b.String()
+}
+
+// WriteNTriples writes the graph as RDF N-Triples, the reverse of
+// ReadNTriples. Node ids and property keys that are not IRIs are
+// resolved against the base, labels are written as rdf:type, nodes
+// without ids are written as blank nodes and edges without a "name" are
+// named "link". The "name"
+// property is written only if it differs from the local name of the
+// node or the node has no other triples, and edge properties other
+// than "name" are not written. Lists set by SetList are written as one
+// triple per item.
+func (g *Graph) WriteNTriples(w io.Writer, opts RDFOptions) error {
+    return g.writeRDF(w, opts, false)
+}
+
+// WriteTurtle writes the graph as RDF Turtle, as WriteNTriples does but
+// grouped by subject and using the prefixes of opts.
+func (g *Graph) WriteTurtle(w io.Writer, opts RDFOptions) error {
+    return g.writeRDF(w, opts, true)
+}
+
+func (g *Graph) writeRDF(w io.Writer, opts RDFOptions, turtle bool) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    rw := &rdfWriter{opts: opts, turtle: turtle, blanks: make(map[*Node]string)}
+    used := make(map[string]bool)
+    for i, node := range g.nodes {
+        id := node.GetProperty("id")
+        if len(id) > 0 && !strings.HasPrefix(id, "_:") {
+            continue
+        }
+        label := strings.TrimPrefix(id, "_:")
+        if len(label) == 0 || !turtleLocal(label) || used[label] {
+            label = "n" + strconv.Itoa(i)
+        }
+        used[label] = true
+        rw.blanks[node] = label
+    }
+
+    bw := bufio.NewWriter(w)
+    if turtle && len(opts.Prefixes) > 0 {
+        prefixes := make([]string, 0, len(opts.Prefixes))
+        for prefix := range opts.Prefixes {
+            prefixes = append(prefixes, prefix)
+        }
+        sort.Strings(prefixes)
+        for _, prefix := range prefixes {
+            bw.WriteString("@prefix " + prefix + ": " + rdfIRITerm(opts.Prefixes[prefix]) + " .\n")
+        }
+        bw.WriteString("\n")
+    }
+
+    children := make(map[*Node][]*Edge)
+    for _, edge := range g.edges() {
+        children[edge.ParentNode] = append(children[edge.ParentNode], edge)
+    }
+
+    for _, node := range g.nodes {
+        subject := rw.subject(node)
+        props := node.copyProperties()
+
+        // the name read from an IRI or blank node label is not repeated
+        derived, blank := rw.blanks[node]
+        if !blank {
+            derived = rdfLocalName(rw.iri(props["id"]))
+        }
+
+        pairs := make([][2]string, 0)
+        for _, label := range node.Labels() {
+            pairs = append(pairs, [2]string{rw.iriTerm(rdfNamespace + "type"), rw.iriTerm(rw.iri(label))})
+        }
+        keys := make([]string, 0, len(props))
+        for key := range props {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+        for _, key := range keys {
+            if key == "id" || (key == "name" && props[key] == derived) {
+                continue
+            }
+            predicate, lang := key, ""
+            if at := strings.LastIndexByte(key, '@'); at > 0 && rdfLangTag(key[at+1:]) {
+                predicate, lang = key[:at], key[at+1:]
+            }
+            items := []Value{StringValue(props[key])}
+            if v, _ := node.GetValue(key); v.Kind() == ListKind {
+                items, _ = v.List()
+            }
+            for _, item := range items {
+                pairs = append(pairs, [2]string{rw.iriTerm(rw.iri(predicate)), rdfLiteralTerm(item.String(), lang)})
+            }
+        }
+
+        for _, edge := range children[node] {
+            name := edge.GetProperty("name")
+            if len(name) == 0 {
+                name = "link"
+            }
+            pairs = append(pairs, [2]string{rw.iriTerm(rw.iri(name)), rw.subject(edge.ChildNode)})
+        }
+
+        // a node without other triples keeps its name so it is not lost
+        if len(pairs) == 0 {
+            name := props["name"]
+            if len(name) == 0 {
+                name = derived
+            }
+            pairs = append(pairs, [2]string{rw.iriTerm(rw.iri("name")), rdfLiteralTerm(name, "")})
+        }
+        if !turtle {
+            for _, pair := range pairs {
+                bw.WriteString(subject + " " + pair[0] + " " + pair[1] + " .\n")
+            }
+            continue
+        }
+        bw.WriteString(subject)
+        for i, pair := range pairs {
+            if i > 0 {
+                bw.WriteString(" ;")
+            }
+            bw.WriteString("\n    " + pair[0] + " " + pair[1])
+        }
+        bw.WriteString(" .\n\n")
+    }
+
+    return bw.Flush()
+}
@@ -0,0 +1,98 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "fmt"
+    "strings"
+    "testing"
+)
+
+func TestRDFRoundTrip(t *testing.T) {
+
+    g := NewGraph("")
+    a, _ := g.AddNode("a", "Alice")
+    b, _ := g.AddNode("b", "b")
+    c, _ := g.AddNode("http://example.org/c", "Cee")
+    g.AddNode("alone", "alone")
+    g.AddNode("_:blank", "blank")
+    a.AddProperty("city", "Paris")
+    g.AddEdge("e1", "KNOWS", 1, a, b)
+    g.AddEdge("e2", "LIKES", 1, b, c)
+
+    for _, opts := range []RDFOptions{{}, {Base: "http://example.org/people/"}} {
+        var buf bytes.Buffer
+        if err := g.WriteNTriples(&buf, opts); err != nil {
+            t.Fatal(err)
+        }
+        h, err := ReadNTriples(&buf, opts)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        if len(h.nodes) != len(g.nodes) {
+            t.Errorf("base %q: read %d nodes, want %d", opts.Base, len(h.nodes), len(g.nodes))
+        }
+        for _, want := range g.nodes {
+            id := want.GetProperty("id")
+            got, ok := h.GetNodeById(id)
+            if !ok {
+                t.Fatalf("base %q: node %q missing", opts.Base, id)
+            }
+            for key, value := range want.Properties {
+                if got.GetProperty(key) != value {
+                    t.Errorf("base %q: node %q has %s %q, want %q", opts.Base, id, key, got.GetProperty(key), value)
+                }
+            }
+            if len(got.Properties) != len(want.Properties) {
+                t.Errorf("base %q: node %q has properties %v", opts.Base, id, got.Properties)
+            }
+        }
+
+        types := make(map[string]string)
+        for _, n := range h.nodes {
+            for _, e := range n.EdgesOfType("KNOWS", "LIKES") {
+                types[e.ParentNode.GetProperty("id")+" "+e.ChildNode.GetProperty("id")] = e.GetProperty("name")
+            }
+        }
+        if types["a b"] != "KNOWS" || types["b http://example.org/c"] != "LIKES" || len(types) != 2 {
+            t.Errorf("base %q: edges %v", opts.Base, types)
+        }
+    }
+}
+
+func TestRDFRepeatedLiterals(t *testing.T) {
+
+    src := `<urn:graph:a> <urn:graph:tag> "x", "y", "x", "z"@en .` + "\n"
+    g, err := ReadTurtle(strings.NewReader(src), RDFOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    a, ok := g.GetNodeById("a")
+    if !ok {
+        t.Fatal("node a missing")
+    }
+    if tags, _ := a.GetList("tag"); fmt.Sprint(tags) != "[x y]" {
+        t.Errorf("tags %v, want [x y]", tags)
+    }
+    if tag := a.GetProperty("tag@en"); tag != "z" {
+        t.Errorf("tag@en %q, want z", tag)
+    }
+
+    var buf bytes.Buffer
+    if err := g.WriteNTriples(&buf, RDFOptions{}); err != nil {
+        t.Fatal(err)
+    }
+    h, err := ReadNTriples(&buf, RDFOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, _ := h.GetNodeById("a")
+    if tags, _ := b.GetList("tag"); fmt.Sprint(tags) != "[x y]" {
+        t.Errorf("tags after round trip %v, want [x y]", tags)
+    }
+}
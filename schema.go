@@ -0,0 +1,378 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Schema declares the node properties and edges a graph allows. Labels
+// maps a node label to the rules for its property keys. Edges lists the
+// allowed edge types; an edge whose type has rules must match one of
+// them, while edges of other types are not restricted. A schema should
+// not be changed while a graph uses it.
+type Schema struct {
+    Labels  map[string]map[string]PropertyRule
+    Edges   []EdgeRule
+}
+
+// PropertyRule declares the kind of a property, which its text must
+// parse as, and whether nodes with the label must have it.
+type PropertyRule struct {
+    Kind      ValueKind
+    Required  bool
+}
+
+// EdgeRule allows edges of Type from nodes with the From label to nodes
+// with the To label, where an empty label matches any node. MaxOut
+// limits the edges of the rule leaving one node and MaxIn those reaching
+// one node, 0 for no limit.
+type EdgeRule struct {
+    Type    string
+    From    string
+    To      string
+    MaxOut  int
+    MaxIn   int
+}
+
+// SchemaError reports a node or edge the schema does not allow.
+type SchemaError struct {
+    Node     *Node
+    Edge     *Edge
+    Reason   string
+    subject  string
+}
+
+func (e *SchemaError) Error() string {
+    return e.subject + ": " + e.Reason
+}
+
+// SchemaErrors lists every violation found by Validate.
+type SchemaErrors []*SchemaError
+
+func (e SchemaErrors) Error() string {
+    parts := make([]string, len(e))
+    for i, err := range e {
+        parts[i] = err.Error()
+    }
+    return strings.Join(parts, "\n")
+}
+
+func nodeError(n *Node, reason string) *SchemaError {
+    return &SchemaError{Node: n, Reason: reason, subject: "Node " + strconv.Quote(n.GetProperty("id"))}
+}
+
+func edgeError(e *Edge, t string, from *Node, to *Node, reason string) *SchemaError {
+    subject := "Edge " + strconv.Quote(t) + " from " + strconv.Quote(from.GetProperty("id")) +
+        " to " + strconv.Quote(to.GetProperty("id"))
+    return &SchemaError{Edge: e, Reason: reason, subject: subject}
+}
+
+// SetSchema sets the schema the graph is checked against, or removes it
+// when s is nil. If the graph does not conform it fails with
+// SchemaErrors and keeps its old schema.
+func (g *Graph) SetSchema(s *Schema) error {
+
+    if g == nil {
+        return errors.New("Graph is nil")
+    }
+
+    if s != nil {
+        if errs := s.validate(g); len(errs) > 0 {
+            return errs
+        }
+    }
+    g.schema = s
+
+    return nil
+}
+
+// Schema returns the schema of the graph, or nil if it has none.
+func (g *Graph) Schema() *Schema {
+    if g == nil {
+        return nil
+    }
+    return g.schema
+}
+
+// Validate checks the graph against its schema and returns every
+// violation as SchemaErrors, or nil if the graph conforms or has no
+// schema.
+func (g *Graph) Validate() error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    if g.schema == nil {
+        return nil
+    }
+    if errs := g.schema.validate(g); len(errs) > 0 {
+        return errs
+    }
+    return nil
+}
+
+// schema returns the schema of the graph holding the node, if any.
+func (n *Node) schema() *Schema {
+
+    n.lock.RLock()
+    g := n.graph
+    n.lock.RUnlock()
+
+    if g == nil {
+        return nil
+    }
+    return g.schema
+}
+
+// checkProperty returns an error if the schema does not allow the node
+// to have the property set to value, or to lack it when present is
+// false.
+func (n *Node) checkProperty(key string, value string, present bool) error {
+
+    s := n.schema()
+    if s == nil {
+        return nil
+    }
+    if reason := s.propertyReason(n.Labels(), key, value, present); len(reason) > 0 {
+        return nodeError(n, reason)
+    }
+    return nil
+}
+
+// labelMatches returns true if label is empty or one of labels.
+func labelMatches(label string, labels []string) bool {
+    return len(label) == 0 || containsString(labels, label)
+}
+
+func labelList(labels []string) string {
+    return "[" + strings.Join(labels, ", ") + "]"
+}
+
+// propertyReason returns why a node with the labels may not have the
+// property, or an empty string if it may.
+func (s *Schema) propertyReason(labels []string, key string, value string, present bool) string {
+
+    for _, label := range labels {
+        rule, ok := s.Labels[label][key]
+        if !ok {
+            continue
+        }
+        if !present {
+            if rule.Required {
+                return "missing property " + key + " required by label " + label
+            }
+            continue
+        }
+        if _, err := ParseValue(rule.Kind, value); err != nil {
+            return "property " + key + " of label " + label + " must be " + rule.Kind.String() +
+                ", not " + strconv.Quote(value)
+        }
+    }
+
+    return ""
+}
+
+// nodeErrors checks the properties of a node against its labels.
+func (s *Schema) nodeErrors(n *Node) []*SchemaError {
+
+    out := make([]*SchemaError, 0)
+    labels := n.Labels()
+    props := n.copyProperties()
+
+    for _, label := range labels {
+        keys := make([]string, 0, len(s.Labels[label]))
+        for key := range s.Labels[label] {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+
+        for _, key := range keys {
+            value, ok := props[key]
+            if reason := s.propertyReason([]string{label}, key, value, ok); len(reason) > 0 {
+                out = append(out, nodeError(n, reason))
+            }
+        }
+    }
+
+    return out
+}
+
+// edgeReason returns why an edge of type t may not join nodes with the
+// labels from and to, or an empty string if it may.
+func (s *Schema) edgeReason(t string, from []string, to []string) string {
+
+    ruled := false
+    for _, r := range s.Edges {
+        if r.Type != t {
+            continue
+        }
+        if labelMatches(r.From, from) && labelMatches(r.To, to) {
+            return ""
+        }
+        ruled = true
+    }
+
+    if !ruled {
+        return ""
+    }
+    return "type not allowed from " + labelList(from) + " to " + labelList(to)
+}
+
+// edgeErrors checks the type of an edge against the labels of its nodes.
+func (s *Schema) edgeErrors(e *Edge) []*SchemaError {
+
+    e.lock.RLock()
+    parent, child, t := e.ParentNode, e.ChildNode, e.kind
+    e.lock.RUnlock()
+
+    if parent == nil || child == nil {
+        return nil
+    }
+    if reason := s.edgeReason(t, parent.Labels(), child.Labels()); len(reason) > 0 {
+        return []*SchemaError{edgeError(e, t, parent, child, reason)}
+    }
+    return nil
+}
+
+// count returns the number of edges of the rule leaving n, or reaching
+// it when outgoing is false.
+func (s *Schema) count(n *Node, r EdgeRule, outgoing bool) int {
+
+    c := 0
+    seen := make(map[*Edge]bool)
+    for _, e := range n.EdgesOfType(r.Type) {
+        // a self loop is listed twice on its node
+        if seen[e] {
+            continue
+        }
+        seen[e] = true
+        if outgoing && e.ParentNode == n && labelMatches(r.To, e.ChildNode.Labels()) {
+            c++
+        }
+        if !outgoing && e.ChildNode == n && labelMatches(r.From, e.ParentNode.Labels()) {
+            c++
+        }
+    }
+
+    return c
+}
+
+// limitReason describes a node with c edges of a rule, more than the
+// rule allows.
+func limitReason(r EdgeRule, outgoing bool, c int) string {
+
+    dir, max, label := "incoming", r.MaxIn, r.From
+    if outgoing {
+        dir, max, label = "outgoing", r.MaxOut, r.To
+    }
+    if len(label) == 0 {
+        label = "any node"
+    }
+    word := " to "
+    if !outgoing {
+        word = " from "
+    }
+
+    return "has " + strconv.Itoa(c) + " " + dir + " " + r.Type + " edges" + word + label +
+        ", at most " + strconv.Itoa(max) + " allowed"
+}
+
+// limitErrors checks the edges of a node against the limits of the
+// rules that apply to it.
+func (s *Schema) limitErrors(n *Node) []*SchemaError {
+
+    out := make([]*SchemaError, 0)
+    labels := n.Labels()
+
+    for _, r := range s.Edges {
+        if r.MaxOut > 0 && labelMatches(r.From, labels) {
+            if c := s.count(n, r, true); c > r.MaxOut {
+                out = append(out, nodeError(n, limitReason(r, true, c)))
+            }
+        }
+        if r.MaxIn > 0 && labelMatches(r.To, labels) {
+            if c := s.count(n, r, false); c > r.MaxIn {
+                out = append(out, nodeError(n, limitReason(r, false, c)))
+            }
+        }
+    }
+
+    return out
+}
+
+// allowEdge returns an error if the schema does not allow one more edge
+// of type t from one node to another.
+func (s *Schema) allowEdge(e *Edge, t string, from *Node, to *Node) error {
+
+    fromLabels := from.Labels()
+    toLabels := to.Labels()
+    if reason := s.edgeReason(t, fromLabels, toLabels); len(reason) > 0 {
+        return edgeError(e, t, from, to, reason)
+    }
+
+    for _, r := range s.Edges {
+        if r.Type != t || !labelMatches(r.From, fromLabels) || !labelMatches(r.To, toLabels) {
+            continue
+        }
+        if r.MaxOut > 0 {
+            if c := s.count(from, r, true) + 1; c > r.MaxOut {
+                return nodeError(from, limitReason(r, true, c))
+            }
+        }
+        if r.MaxIn > 0 {
+            if c := s.count(to, r, false) + 1; c > r.MaxIn {
+                return nodeError(to, limitReason(r, false, c))
+            }
+        }
+    }
+
+    return nil
+}
+
+// checkAround returns the first violation found at a node, its edges or
+// the edge limits of its neighbours, after a change to its labels.
+func (s *Schema) checkAround(n *Node) error {
+
+    errs := append(s.nodeErrors(n), s.limitErrors(n)...)
+
+    n.lock.RLock()
+    edges := append([]*Edge{}, n.Edges...)
+    n.lock.RUnlock()
+
+    seen := map[*Node]bool{n: true}
+    for _, e := range edges {
+        errs = append(errs, s.edgeErrors(e)...)
+        if m := otherEnd(e, n); m != nil && !seen[m] {
+            seen[m] = true
+            errs = append(errs, s.limitErrors(m)...)
+        }
+    }
+
+    if len(errs) > 0 {
+        return errs[0]
+    }
+    return nil
+}
+
+// validate returns every violation in the graph.
+func (s *Schema) validate(g *Graph) SchemaErrors {
+
+    out := make(SchemaErrors, 0)
+    for _, n := range g.nodes {
+        out = append(out, s.nodeErrors(n)...)
+        out = append(out, s.limitErrors(n)...)
+    }
+    for _, e := range g.edges() {
+        out = append(out, s.edgeErrors(e)...)
+    }
+
+    return out
+}
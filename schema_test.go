@@ -0,0 +1,166 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "encoding/json"
+    "testing"
+)
+
+// schemaGraph is a triangle of people whose "group" must be a time, so
+// writing a community id or color there is rejected.
+func schemaGraph(t *testing.T) *Graph {
+
+    g := NewGraph("schema")
+    a, _ := g.AddNode("a", "A", "Person")
+    b, _ := g.AddNode("b", "B", "Person")
+    c, _ := g.AddNode("c", "C", "Person")
+    g.AddEdge("ab", "KNOWS", 1, a, b)
+    g.AddEdge("bc", "KNOWS", 1, b, c)
+    g.AddEdge("ca", "KNOWS", 1, c, a)
+
+    err := g.SetSchema(&Schema{Labels: map[string]map[string]PropertyRule{
+        "Person": {"group": {Kind: TimeKind}},
+    }})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return g
+}
+
+func TestSchemaRejectsWriteBack(t *testing.T) {
+
+    g := schemaGraph(t)
+    if _, err := g.Louvain("group"); err == nil {
+        t.Errorf("Louvain wrote a rejected community id")
+    }
+    if _, err := g.LabelPropagation(1, "group"); err == nil {
+        t.Errorf("LabelPropagation wrote a rejected community id")
+    }
+    if _, err := g.Color(ColorInOrder, "group"); err == nil {
+        t.Errorf("Color wrote a rejected color")
+    }
+    if _, err := g.Louvain("community"); err != nil {
+        t.Errorf("Louvain with an unruled property: %v", err)
+    }
+    if err := g.Validate(); err != nil {
+        t.Errorf("graph no longer conforms: %v", err)
+    }
+}
+
+func TestUnmarshalJSONChecksSchema(t *testing.T) {
+
+    other := NewGraph("other")
+    n, _ := other.AddNode("x", "X", "Person")
+    n.AddProperty("group", "soon")
+    data, err := json.Marshal(other)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    g := schemaGraph(t)
+    err = json.Unmarshal(data, g)
+    if _, ok := err.(SchemaErrors); !ok {
+        t.Fatalf("got error %v, want SchemaErrors", err)
+    }
+    if g.NumNodes() != 3 || g.id != "schema" {
+        t.Errorf("a rejected decode changed the graph")
+    }
+}
+
+func TestSchema(t *testing.T) {
+
+    g := NewGraph("people")
+    alice, _ := g.AddNode("alice", "Alice")
+    alice.SetInt("age", 30)
+    alice.AddLabel("Person")
+    paris, _ := g.AddNode("paris", "Paris", "City")
+    rome, _ := g.AddNode("rome", "Rome", "City")
+
+    schema := &Schema{
+        Labels: map[string]map[string]PropertyRule{
+            "Person": {"age": {Kind: IntKind, Required: true}},
+        },
+        Edges: []EdgeRule{
+            {Type: "knows", From: "Person", To: "Person"},
+            {Type: "lives_in", From: "Person", To: "City", MaxOut: 1},
+        },
+    }
+    if err := g.SetSchema(schema); err != nil {
+        t.Fatal(err)
+    }
+
+    err := alice.AddProperty("age", "old")
+    if want := `Node "alice": property age of label Person must be int, not "old"`; err == nil || err.Error() != want {
+        t.Errorf("got error %v, want %s", err, want)
+    }
+    if age, _ := alice.GetInt("age"); age != 30 {
+        t.Errorf("rejected write changed age to %d", age)
+    }
+    if err := alice.RemProperty("age"); err == nil {
+        t.Errorf("removing a required property did not fail")
+    }
+
+    // a label is only added once the node has its required properties
+    bob, _ := g.AddNode("bob", "Bob")
+    if err := bob.AddLabel("Person"); err == nil || bob.HasLabel("Person") {
+        t.Errorf("label added without a required property: %v", err)
+    }
+    bob.AddProperty("age", "40")
+    if err := bob.AddLabel("Person"); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := g.AddEdge("k1", "knows", 1, alice, paris); err == nil {
+        t.Errorf("knows edge from a Person to a City was allowed")
+    }
+    if err := g.AddEdge("k2", "knows", 1, alice, bob); err != nil {
+        t.Errorf("knows edge between people: %v", err)
+    }
+    if err := g.AddEdge("l1", "lives_in", 1, alice, paris); err != nil {
+        t.Fatal(err)
+    }
+    err = g.AddEdge("l2", "lives_in", 1, alice, rome)
+    if want := `Node "alice": has 2 outgoing lives_in edges to City, at most 1 allowed`; err == nil || err.Error() != want {
+        t.Errorf("got error %v, want %s", err, want)
+    }
+    if err := g.AddEdge("v1", "visited", 1, paris, rome); err != nil {
+        t.Errorf("edge of a type without rules: %v", err)
+    }
+    for _, e := range paris.EdgesOfType("lives_in") {
+        if err := e.SetType("knows"); err == nil || e.Type() != "lives_in" {
+            t.Errorf("edge type changed to one the labels do not allow: %v", err)
+        }
+    }
+    if err := paris.RemoveLabel("City"); err == nil || !paris.HasLabel("City") {
+        t.Errorf("removed the label a lives_in edge needs: %v", err)
+    }
+    if err := g.Validate(); err != nil {
+        t.Errorf("graph does not conform: %v", err)
+    }
+
+    stricter := &Schema{Labels: map[string]map[string]PropertyRule{
+        "Person": {"email": {Kind: StringKind, Required: true}},
+    }}
+    err = g.SetSchema(stricter)
+    if errs, ok := err.(SchemaErrors); !ok || len(errs) != 2 || errs[0].Node != alice {
+        t.Errorf("got error %v, want alice and bob missing email", err)
+    }
+    if g.Schema() != schema {
+        t.Errorf("a rejected schema replaced the old one")
+    }
+
+    if err := g.SetSchema(nil); err != nil || g.Schema() != nil {
+        t.Fatalf("removing the schema: %v", err)
+    }
+    if err := alice.AddProperty("age", "old"); err != nil {
+        t.Errorf("write rejected without a schema: %v", err)
+    }
+    if err := g.Validate(); err != nil {
+        t.Errorf("graph without a schema does not validate: %v", err)
+    }
+}
@@ -0,0 +1,386 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+// A snapshot is a compact binary encoding of a graph:
+//
+//  magic     "GSNP"
+//  version   uvarint
+//  sections  tag uvarint, length uvarint, payload
+//  end       tag 0
+//  checksum  CRC-32 (IEEE) of everything before it, 4 bytes big endian
+//
+// The sections are
+//
+//  1 strings  count, then every string as length and bytes
+//  2 graph    graph id as a string index
+//  3 nodes    count, then per node its properties
+//  4 edges    count, then per edge the parent and child node index,
+//             the distance as 8 bytes little endian and its properties
+//  5 labels   per node its label count and label string indexes, then
+//             per edge its type as a string index
+//
+// where properties are a count followed by key and value string indexes,
+// and every number not otherwise noted is a uvarint. Readers skip
+// sections they do not know, so later versions can add sections without
+// breaking older readers.
+
+import (
+    "bufio"
+    "encoding/binary"
+    "errors"
+    "hash"
+    "hash/crc32"
+    "io"
+    "math"
+    "sort"
+)
+
+const (
+    snapshotMagic    = "GSNP"
+    snapshotVersion  = 1
+)
+
+const (
+    sectionEnd = iota
+    sectionStrings
+    sectionGraph
+    sectionNodes
+    sectionEdges
+    sectionLabels
+)
+
+// snapshotEncoder builds section payloads and interns strings.
+type snapshotEncoder struct {
+    strings  []string
+    index    map[string]uint64
+    keys     []string
+    scratch  [binary.MaxVarintLen64]byte
+}
+
+func (s *snapshotEncoder) uvarint(buf []byte, v uint64) []byte {
+    n := binary.PutUvarint(s.scratch[:], v)
+    return append(buf, s.scratch[:n]...)
+}
+
+func (s *snapshotEncoder) str(buf []byte, v string) []byte {
+    i, ok := s.index[v]
+    if !ok {
+        i = uint64(len(s.strings))
+        s.index[v] = i
+        s.strings = append(s.strings, v)
+    }
+    return s.uvarint(buf, i)
+}
+
+func (s *snapshotEncoder) properties(buf []byte, props map[string]string) []byte {
+
+    s.keys = s.keys[:0]
+    for key := range props {
+        s.keys = append(s.keys, key)
+    }
+    sort.Strings(s.keys)
+
+    buf = s.uvarint(buf, uint64(len(s.keys)))
+    for _, key := range s.keys {
+        buf = s.str(buf, key)
+        buf = s.str(buf, props[key])
+    }
+    return buf
+}
+
+// Save writes a binary snapshot of the graph to w, see the layout above.
+func (g *Graph) Save(w io.Writer) error {
+
+    if g == nil {
+        return errors.New("Graph is empty or nil")
+    }
+
+    s := &snapshotEncoder{index: make(map[string]uint64)}
+    idx := g.nodeIndexes()
+    edges := g.edges()
+
+    graph := s.str(nil, g.id)
+
+    nodes := s.uvarint(make([]byte, 0, 8*len(g.nodes)), uint64(len(g.nodes)))
+    for _, node := range g.nodes {
+        node.lock.RLock()
+        nodes = s.properties(nodes, node.Properties)
+        node.lock.RUnlock()
+    }
+
+    var distance [8]byte
+    links := s.uvarint(make([]byte, 0, 16*len(edges)), uint64(len(edges)))
+    for _, edge := range edges {
+        links = s.uvarint(links, uint64(idx[edge.ParentNode]))
+        links = s.uvarint(links, uint64(idx[edge.ChildNode]))
+        binary.LittleEndian.PutUint64(distance[:], math.Float64bits(edge.Distance))
+        links = append(links, distance[:]...)
+        edge.lock.RLock()
+        links = s.properties(links, edge.Properties)
+        edge.lock.RUnlock()
+    }
+
+    labels := make([]byte, 0, len(g.nodes)+len(edges))
+    for _, node := range g.nodes {
+        node.lock.RLock()
+        labels = s.uvarint(labels, uint64(len(node.labels)))
+        for _, label := range node.labels {
+            labels = s.str(labels, label)
+        }
+        node.lock.RUnlock()
+    }
+    for _, edge := range edges {
+        labels = s.str(labels, edge.Type())
+    }
+
+    table := s.uvarint(nil, uint64(len(s.strings)))
+    for _, v := range s.strings {
+        table = s.uvarint(table, uint64(len(v)))
+        table = append(table, v...)
+    }
+
+    crc := crc32.NewIEEE()
+    bw := bufio.NewWriter(io.MultiWriter(w, crc))
+
+    out := s.uvarint([]byte(snapshotMagic), snapshotVersion)
+    bw.Write(out)
+
+    sections := []struct {
+        tag      uint64
+        payload  []byte
+    }{
+        {sectionStrings, table},
+        {sectionGraph, graph},
+        {sectionNodes, nodes},
+        {sectionEdges, links},
+        {sectionLabels, labels},
+    }
+    for _, section := range sections {
+        head := s.uvarint(nil, section.tag)
+        head = s.uvarint(head, uint64(len(section.payload)))
+        bw.Write(head)
+        bw.Write(section.payload)
+    }
+    bw.Write(s.uvarint(nil, sectionEnd))
+
+    if err := bw.Flush(); err != nil {
+        return err
+    }
+
+    var sum [4]byte
+    binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+    _, err := w.Write(sum[:])
+    return err
+}
+
+// snapshotReader reads from r while feeding the checksum.
+type snapshotReader struct {
+    r    *bufio.Reader
+    crc  hash.Hash32
+}
+
+func (s *snapshotReader) ReadByte() (byte, error) {
+    b, err := s.r.ReadByte()
+    if err == nil {
+        s.crc.Write([]byte{b})
+    }
+    return b, err
+}
+
+func (s *snapshotReader) read(n uint64) ([]byte, error) {
+    buf, err := io.ReadAll(io.LimitReader(s.r, int64(n)))
+    if err == nil && uint64(len(buf)) < n {
+        err = io.ErrUnexpectedEOF
+    }
+    s.crc.Write(buf)
+    return buf, err
+}
+
+// snapshotDecoder walks a section payload.
+type snapshotDecoder struct {
+    buf      []byte
+    strings  []string
+    err      error
+}
+
+var errSnapshotCorrupt = errors.New("Snapshot is corrupt")
+
+func (d *snapshotDecoder) uvarint() uint64 {
+    if d.err != nil {
+        return 0
+    }
+    v, n := binary.Uvarint(d.buf)
+    if n <= 0 {
+        d.err = errSnapshotCorrupt
+        return 0
+    }
+    d.buf = d.buf[n:]
+    return v
+}
+
+// count reads a number of items each taking at least size bytes.
+func (d *snapshotDecoder) count(size int) int {
+    v := d.uvarint()
+    if v > uint64(len(d.buf)/size) {
+        d.err = errSnapshotCorrupt
+        return 0
+    }
+    return int(v)
+}
+
+func (d *snapshotDecoder) str() string {
+    i := d.uvarint()
+    if d.err != nil {
+        return ""
+    }
+    if i >= uint64(len(d.strings)) {
+        d.err = errSnapshotCorrupt
+        return ""
+    }
+    return d.strings[i]
+}
+
+func (d *snapshotDecoder) float() float64 {
+    if d.err != nil {
+        return 0
+    }
+    if len(d.buf) < 8 {
+        d.err = errSnapshotCorrupt
+        return 0
+    }
+    v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+    d.buf = d.buf[8:]
+    return v
+}
+
+func (d *snapshotDecoder) properties(props map[string]string) {
+    for n := d.count(2); n > 0 && d.err == nil; n-- {
+        key := d.str()
+        props[key] = d.str()
+    }
+}
+
+// Load reads a graph written by Save from r. Snapshots do not hold a
+// schema, so the graph has none until SetSchema checks it against one.
+func Load(r io.Reader) (*Graph, error) {
+
+    s := &snapshotReader{r: bufio.NewReader(r), crc: crc32.NewIEEE()}
+
+    magic, err := s.read(uint64(len(snapshotMagic)))
+    if err != nil || string(magic) != snapshotMagic {
+        return nil, errors.New("Not a graph snapshot")
+    }
+    version, err := binary.ReadUvarint(s)
+    if err != nil {
+        return nil, err
+    }
+    if version < 1 {
+        return nil, errors.New("Unsupported snapshot version")
+    }
+
+    sections := make(map[uint64][]byte)
+    for {
+        tag, err := binary.ReadUvarint(s)
+        if err != nil {
+            return nil, err
+        }
+        if tag == sectionEnd {
+            break
+        }
+        length, err := binary.ReadUvarint(s)
+        if err != nil {
+            return nil, err
+        }
+        payload, err := s.read(length)
+        if err != nil {
+            return nil, err
+        }
+        sections[tag] = payload
+    }
+
+    var sum [4]byte
+    if _, err := io.ReadFull(s.r, sum[:]); err != nil {
+        return nil, err
+    }
+    if binary.BigEndian.Uint32(sum[:]) != s.crc.Sum32() {
+        return nil, errors.New("Snapshot checksum mismatch")
+    }
+
+    d := &snapshotDecoder{buf: sections[sectionStrings]}
+    if len(d.buf) > 0 {
+        d.strings = make([]string, d.count(1))
+        for i := range d.strings {
+            n := d.uvarint()
+            if n > uint64(len(d.buf)) {
+                return nil, errSnapshotCorrupt
+            }
+            d.strings[i] = string(d.buf[:n])
+            d.buf = d.buf[n:]
+        }
+    }
+
+    d.buf = sections[sectionGraph]
+    g := NewGraph("")
+    if len(d.buf) > 0 {
+        g.id = d.str()
+    }
+
+    d.buf = sections[sectionNodes]
+    nodes := make([]*Node, 0)
+    if len(d.buf) > 0 {
+        nodes = make([]*Node, d.count(1))
+    }
+    for i := range nodes {
+        nodes[i] = NewNode()
+        d.properties(nodes[i].Properties)
+    }
+
+    // labels follow the node order and types the edge order
+    labels := &snapshotDecoder{buf: sections[sectionLabels], strings: d.strings}
+    hasLabels := len(labels.buf) > 0
+    for i := 0; i < len(nodes) && hasLabels; i++ {
+        for n := labels.count(1); n > 0 && labels.err == nil; n-- {
+            nodes[i].AddLabel(labels.str())
+        }
+    }
+
+    d.buf = sections[sectionEdges]
+    count := 0
+    if len(d.buf) > 0 {
+        count = d.count(11)
+    }
+    for i := 0; i < count && d.err == nil; i++ {
+        parent := d.uvarint()
+        child := d.uvarint()
+        if parent >= uint64(len(nodes)) || child >= uint64(len(nodes)) {
+            return nil, errSnapshotCorrupt
+        }
+        e := NewEdge()
+        e.SetDistance(d.float())
+        d.properties(e.Properties)
+        if hasLabels {
+            e.kind = labels.str()
+        }
+        if err := e.Link(nodes[parent], nodes[child]); err != nil {
+            return nil, err
+        }
+    }
+
+    if d.err != nil {
+        return nil, d.err
+    }
+    if labels.err != nil {
+        return nil, labels.err
+    }
+
+    g.nodes = make([]*Node, 0, len(nodes))
+    for _, n := range nodes {
+        g.insertNode(n)
+    }
+
+    return g, nil
+}
@@ -0,0 +1,138 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "encoding/json"
+    "math/rand"
+    "strconv"
+    "testing"
+)
+
+// snapshotGraph builds a random labelled graph with typed edges.
+func snapshotGraph(nodes int, edges int) *Graph {
+
+    r := rand.New(rand.NewSource(1))
+    g := NewGraph("bench")
+    list := make([]*Node, nodes)
+    for i := range list {
+        id := strconv.Itoa(i)
+        list[i], _ = g.AddNode("n"+id, "node "+id, []string{"Person", "Place"}[i%2])
+        list[i].AddProperty("rank", strconv.Itoa(r.Intn(100)))
+    }
+    types := []string{"KNOWS", "LIKES", "VISITED"}
+    for i := 0; i < edges; i++ {
+        g.AddEdge("e"+strconv.Itoa(i), types[r.Intn(len(types))], r.Float64()+0.1,
+            list[r.Intn(nodes)], list[r.Intn(nodes)])
+    }
+
+    return g
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+
+    g := snapshotGraph(200, 1000)
+    a := g.nodes[0]
+    a.SetInt("age", 42)
+    g.AddEdge("loop", "SELF", 2, a, a)
+    g.AddEdge("dup", "KNOWS", 1, g.nodes[1], g.nodes[2])
+    g.nodes[1].Edges[len(g.nodes[1].Edges)-1].SetType("LIKES")
+
+    var buf bytes.Buffer
+    if err := g.Save(&buf); err != nil {
+        t.Fatal(err)
+    }
+    h, err := Load(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    want, _ := json.Marshal(g)
+    got, _ := json.Marshal(h)
+    if !bytes.Equal(want, got) {
+        t.Fatalf("snapshot round trip changed the graph")
+    }
+    for i, n := range h.nodes {
+        if n.graph != h || n.index != i {
+            t.Fatalf("node %d not attached to the loaded graph", i)
+        }
+        if len(n.EdgesOfType("KNOWS", "LIKES", "VISITED", "SELF")) != len(n.Edges) {
+            t.Fatalf("node %d has edges missing from its type index", i)
+        }
+    }
+    if len(h.NodesByLabel("Person")) != 100 {
+        t.Fatalf("label index has %d people, want 100", len(h.NodesByLabel("Person")))
+    }
+
+    buf.Reset()
+    g.Save(&buf)
+    data := buf.Bytes()
+    data[len(data)/2] ^= 1
+    if _, err := Load(bytes.NewReader(data)); err == nil {
+        t.Fatalf("corrupt snapshot loaded without error")
+    }
+}
+
+// The benchmarks compare snapshots with JSON on 20,000 nodes and
+// 200,000 edges.
+
+var benchGraph *Graph
+
+func benchmarkGraph(b *testing.B) *Graph {
+    if benchGraph == nil {
+        benchGraph = snapshotGraph(20000, 200000)
+    }
+    b.ResetTimer()
+    return benchGraph
+}
+
+func BenchmarkSave(b *testing.B) {
+    g := benchmarkGraph(b)
+    var buf bytes.Buffer
+    for i := 0; i < b.N; i++ {
+        buf.Reset()
+        if err := g.Save(&buf); err != nil {
+            b.Fatal(err)
+        }
+    }
+    b.ReportMetric(float64(buf.Len()), "bytes")
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+    g := benchmarkGraph(b)
+    var data []byte
+    for i := 0; i < b.N; i++ {
+        var err error
+        if data, err = json.Marshal(g); err != nil {
+            b.Fatal(err)
+        }
+    }
+    b.ReportMetric(float64(len(data)), "bytes")
+}
+
+func BenchmarkLoad(b *testing.B) {
+    g := benchmarkGraph(b)
+    var buf bytes.Buffer
+    g.Save(&buf)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := Load(bytes.NewReader(buf.Bytes())); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+    g := benchmarkGraph(b)
+    data, _ := json.Marshal(g)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if err := json.Unmarshal(data, NewGraph("")); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
@@ -0,0 +1,167 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+)
+
+// Stats is a summary report of a graph.
+type Stats struct {
+    Nodes          int
+    Edges          int
+    Density        float64
+    // InDegree and OutDegree map a degree to the number of nodes with
+    // that many parent or child links.
+    InDegree       map[int]int
+    OutDegree      map[int]int
+    // Eccentricity is the greatest distance from a node to any other
+    // node, +Inf when some node can not be reached.
+    Eccentricity   map[*Node]float64
+    Diameter       float64
+    Radius         float64
+    Center         []*Node
+    Periphery      []*Node
+    // Assortativity is the correlation between the degrees of linked
+    // nodes, 0 when all degrees are equal.
+    Assortativity  float64
+}
+
+// Stats returns a summary report of the graph, following edges from
+// parent to child. Distances are the shortest path distances using
+// Edge.Distance, and assortativity compares the out-degree of parents
+// with the in-degree of children.
+func (g *Graph) Stats() (Stats, error) {
+    return g.stats(true)
+}
+
+// UndirectedStats returns the report of Stats for the graph with edges
+// followed both ways. Density counts every edge as linking a pair of
+// nodes once, and assortativity uses the total degree of nodes.
+func (g *Graph) UndirectedStats() (Stats, error) {
+    return g.stats(false)
+}
+
+func (g *Graph) stats(directed bool) (Stats, error) {
+
+    if g == nil {
+        return Stats{}, errors.New("Graph is empty or nil")
+    }
+
+    edges := g.edges()
+    n := len(g.nodes)
+
+    s := Stats{
+        Nodes:         n,
+        Edges:         len(edges),
+        InDegree:      make(map[int]int),
+        OutDegree:     make(map[int]int),
+        Eccentricity:  make(map[*Node]float64, n),
+        Center:        make([]*Node, 0),
+        Periphery:     make([]*Node, 0),
+    }
+
+    if n > 1 {
+        s.Density = float64(len(edges)) / float64(n*(n-1))
+        if !directed {
+            s.Density *= 2
+        }
+    }
+
+    in := make(map[*Node]int, n)
+    out := make(map[*Node]int, n)
+    for _, node := range g.nodes {
+        parent, child := node.NumLinks()
+        in[node] = parent
+        out[node] = child
+        s.InDegree[parent]++
+        s.OutDegree[child]++
+    }
+
+    // eccentricity from the shortest paths leaving every node
+    adj := g.arcs(directed)
+    ecc := make([]float64, n)
+    for u := range g.nodes {
+        dist, _ := shortestPaths(adj, u)
+        for _, d := range dist {
+            if d > ecc[u] {
+                ecc[u] = d
+            }
+        }
+        s.Eccentricity[g.nodes[u]] = ecc[u]
+    }
+
+    if n > 0 {
+        s.Radius = math.Inf(1)
+    }
+    for _, e := range ecc {
+        if e > s.Diameter {
+            s.Diameter = e
+        }
+        if e < s.Radius {
+            s.Radius = e
+        }
+    }
+    for u, e := range ecc {
+        if e == s.Radius {
+            s.Center = append(s.Center, g.nodes[u])
+        }
+        if e == s.Diameter {
+            s.Periphery = append(s.Periphery, g.nodes[u])
+        }
+    }
+
+    // degree assortativity as the Pearson correlation over edge ends
+    var xs, ys []float64
+    for _, edge := range edges {
+        p, c := edge.ParentNode, edge.ChildNode
+        if directed {
+            xs = append(xs, float64(out[p]))
+            ys = append(ys, float64(in[c]))
+        } else {
+            dp := float64(in[p] + out[p])
+            dc := float64(in[c] + out[c])
+            xs = append(xs, dp, dc)
+            ys = append(ys, dc, dp)
+        }
+    }
+    s.Assortativity = correlation(xs, ys)
+
+    return s, nil
+}
+
+// correlation returns the Pearson correlation of two samples, or 0 if
+// either does not vary.
+func correlation(xs []float64, ys []float64) float64 {
+
+    if len(xs) == 0 {
+        return 0
+    }
+
+    var mx, my float64
+    for i := range xs {
+        mx += xs[i]
+        my += ys[i]
+    }
+    mx /= float64(len(xs))
+    my /= float64(len(ys))
+
+    var cov, vx, vy float64
+    for i := range xs {
+        dx := xs[i] - mx
+        dy := ys[i] - my
+        cov += dx * dy
+        vx += dx * dx
+        vy += dy * dy
+    }
+
+    if vx == 0 || vy == 0 {
+        return 0
+    }
+
+    return cov / math.Sqrt(vx*vy)
+}
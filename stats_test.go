@@ -0,0 +1,76 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "math"
+    "reflect"
+    "testing"
+)
+
+func TestStats(t *testing.T) {
+
+    // the path a b c d with distances 1, 2 and 3
+    g := edgeGraph(t, "a b 1", "b c 2", "c d 3")
+
+    s, err := g.UndirectedStats()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if s.Nodes != 4 || s.Edges != 3 || s.Density != 0.5 {
+        t.Errorf("%d nodes, %d edges, density %v, want 4, 3 and 0.5", s.Nodes, s.Edges, s.Density)
+    }
+    for i, want := range []float64{6, 5, 3, 6} {
+        if got := s.Eccentricity[g.nodes[i]]; got != want {
+            t.Errorf("%s has eccentricity %v, want %v", g.nodes[i].GetProperty("id"), got, want)
+        }
+    }
+    if s.Diameter != 6 || s.Radius != 3 {
+        t.Errorf("diameter %v and radius %v, want 6 and 3", s.Diameter, s.Radius)
+    }
+    if got := nodeNames(s.Center); got != "c" {
+        t.Errorf("center %q, want c", got)
+    }
+    if got := nodeNames(s.Periphery); got != "a d" {
+        t.Errorf("periphery %q, want a d", got)
+    }
+    // the ends of a path of four nodes have degrees 1 2, 2 2 and 2 1
+    if math.Abs(s.Assortativity+0.5) > 1e-12 {
+        t.Errorf("assortativity %v, want -0.5", s.Assortativity)
+    }
+
+    s, err = g.Stats()
+    if err != nil {
+        t.Fatal(err)
+    }
+    // only a reaches every other node
+    if s.Density != 0.25 || !math.IsInf(s.Diameter, 1) || s.Radius != 6 {
+        t.Errorf("directed density %v, diameter %v, radius %v, want 0.25, +Inf and 6", s.Density, s.Diameter, s.Radius)
+    }
+    if got := nodeNames(s.Center); got != "a" {
+        t.Errorf("directed center %q, want a", got)
+    }
+    if got := nodeNames(s.Periphery); got != "b c d" {
+        t.Errorf("directed periphery %q, want b c d", got)
+    }
+
+    star := edgeGraph(t, "hub a", "hub b", "hub c")
+    s, err = star.Stats()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(s.InDegree, map[int]int{0: 1, 1: 3}) || !reflect.DeepEqual(s.OutDegree, map[int]int{3: 1, 0: 3}) {
+        t.Errorf("degrees in %v and out %v, want in map[0:1 1:3] and out map[0:3 3:1]", s.InDegree, s.OutDegree)
+    }
+    if s.Assortativity != 0 {
+        t.Errorf("star assortativity %v, want 0", s.Assortativity)
+    }
+
+    var empty *Graph
+    if _, err := empty.Stats(); err == nil {
+        t.Errorf("stats of a nil graph did not fail")
+    }
+}
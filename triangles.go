@@ -0,0 +1,139 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+// triangles counts the triangles through every node, ignoring edge
+// direction, self-loops and parallel edges. Every edge is oriented from
+// the node of lower degree to the node of higher degree so that each
+// triangle is found exactly once by intersecting short lists.
+func (g *Graph) triangles() (count []int, total int, degree []int) {
+
+    adj := g.neighbourSets()
+    n := len(adj)
+
+    degree = make([]int, n)
+    for u := range adj {
+        degree[u] = len(adj[u])
+    }
+
+    before := func(u, v int) bool {
+        return degree[u] < degree[v] || (degree[u] == degree[v] && u < v)
+    }
+
+    out := make([][]int, n)
+    for u := range adj {
+        for _, v := range adj[u] {
+            if before(u, v) {
+                out[u] = append(out[u], v)
+            }
+        }
+    }
+
+    count = make([]int, n)
+    mark := make([]bool, n)
+    for u := range out {
+        for _, v := range out[u] {
+            mark[v] = true
+        }
+        for _, v := range out[u] {
+            for _, w := range out[v] {
+                if mark[w] {
+                    count[u]++
+                    count[v]++
+                    count[w]++
+                    total++
+                }
+            }
+        }
+        for _, v := range out[u] {
+            mark[v] = false
+        }
+    }
+
+    return
+}
+
+// Triangles returns the number of triangles each node is part of and the
+// number of triangles in the graph. Edges are treated as undirected.
+func (g *Graph) Triangles() (map[*Node]int, int) {
+
+    out := make(map[*Node]int)
+
+    if g == nil {
+        return out, 0
+    }
+
+    count, total, _ := g.triangles()
+    for i, node := range g.nodes {
+        out[node] = count[i]
+    }
+
+    return out, total
+}
+
+// Clustering returns the local clustering coefficient of every node,
+// the share of pairs of its neighbours that are linked to each other.
+// Nodes with fewer than two neighbours have a coefficient of 0. Edges
+// are treated as undirected.
+func (g *Graph) Clustering() map[*Node]float64 {
+
+    out := make(map[*Node]float64)
+
+    if g == nil {
+        return out
+    }
+
+    count, _, degree := g.triangles()
+    for i, node := range g.nodes {
+        out[node] = localClustering(count[i], degree[i])
+    }
+
+    return out
+}
+
+func localClustering(triangles int, degree int) float64 {
+    if degree < 2 {
+        return 0
+    }
+    return 2 * float64(triangles) / float64(degree*(degree-1))
+}
+
+// AverageClustering returns the mean local clustering coefficient over
+// all nodes.
+func (g *Graph) AverageClustering() float64 {
+
+    if g == nil || len(g.nodes) == 0 {
+        return 0
+    }
+
+    count, _, degree := g.triangles()
+    sum := 0.0
+    for i := range count {
+        sum += localClustering(count[i], degree[i])
+    }
+
+    return sum / float64(len(count))
+}
+
+// Transitivity returns the global clustering coefficient, three times
+// the number of triangles divided by the number of connected triples.
+func (g *Graph) Transitivity() float64 {
+
+    if g == nil {
+        return 0
+    }
+
+    _, total, degree := g.triangles()
+    triples := 0
+    for _, d := range degree {
+        triples += d * (d - 1) / 2
+    }
+    if triples == 0 {
+        return 0
+    }
+
+    return 3 * float64(total) / float64(triples)
+}
@@ -0,0 +1,47 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "math"
+    "testing"
+)
+
+func TestTriangles(t *testing.T) {
+
+    // the paw, a triangle a b c with d hanging off c, plus a parallel
+    // edge, a reverse edge and a self-loop that change nothing
+    g := edgeGraph(t, "a b", "b c", "c a", "c d", "a b", "b a", "d d")
+    perNode, total := g.Triangles()
+    clustering := g.Clustering()
+
+    want := []struct {
+        triangles   int
+        clustering  float64
+    }{{1, 1}, {1, 1}, {1, 1.0 / 3}, {0, 0}}
+    for i, n := range g.nodes {
+        if perNode[n] != want[i].triangles || math.Abs(clustering[n]-want[i].clustering) > 1e-12 {
+            t.Errorf("%s: %d triangles and clustering %v, want %d and %v", n.GetProperty("id"),
+                perNode[n], clustering[n], want[i].triangles, want[i].clustering)
+        }
+    }
+    if total != 1 {
+        t.Errorf("%d triangles, want 1", total)
+    }
+    if got := g.AverageClustering(); math.Abs(got-7.0/12) > 1e-12 {
+        t.Errorf("average clustering %v, want 7/12", got)
+    }
+    // three times one triangle over the five paths of two edges
+    if got := g.Transitivity(); math.Abs(got-0.6) > 1e-12 {
+        t.Errorf("transitivity %v, want 0.6", got)
+    }
+
+    k4 := edgeGraph(t, "a b", "a c", "a d", "b c", "b d", "c d")
+    perNode, total = k4.Triangles()
+    if total != 4 || perNode[k4.nodes[0]] != 3 || k4.Transitivity() != 1 || k4.AverageClustering() != 1 {
+        t.Errorf("K4 has %d triangles, %d at a, transitivity %v, want 4, 3 and 1", total, perNode[k4.nodes[0]], k4.Transitivity())
+    }
+}
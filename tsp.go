@@ -0,0 +1,313 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "errors"
+    "math"
+    "sort"
+)
+
+// TourStrategy selects how the first tour is built.
+type TourStrategy int
+
+const (
+    // TourNearestNeighbour always travels to the closest unvisited stop.
+    TourNearestNeighbour TourStrategy = iota
+    // TourChristofides shortcuts an Eulerian circuit of a minimum
+    // spanning tree joined with a pairing of its odd degree stops.
+    TourChristofides
+)
+
+// tourEpsilon ignores improvements lost to floating point error.
+const tourEpsilon = 1e-9
+
+// Tour returns an approximate shortest closed tour visiting every stop,
+// starting and ending at the first one. Stops do not need to be linked
+// directly, the shortest path between them is used. Edges are treated as
+// undirected and Edge.Distance is the cost. When improve is true the
+// tour is refined with 2-opt and Or-opt moves.
+//
+// The stops are returned in visiting order, without repeating the first
+// stop at the end, along with the path of graph edges walked.
+func (g *Graph) Tour(stops []*Node, strategy TourStrategy, improve bool) ([]*Node, Path, error) {
+
+    if g == nil {
+        return nil, Path{}, errors.New("Graph is empty or nil")
+    }
+    if len(stops) == 0 {
+        return nil, Path{}, errors.New("Stops required")
+    }
+
+    idx := g.nodeIndexes()
+    seen := make(map[*Node]bool)
+    for _, stop := range stops {
+        if _, ok := idx[stop]; !ok {
+            return nil, Path{}, errors.New("Node is not part of the graph")
+        }
+        if seen[stop] {
+            return nil, Path{}, errors.New("Stops must be unique")
+        }
+        seen[stop] = true
+    }
+
+    // metric closure over the stops
+    adj := g.arcs(false)
+    dist := make([][]float64, len(stops))
+    via := make([][]incidence, len(stops))
+    for i, stop := range stops {
+        d, p := shortestPaths(adj, idx[stop])
+        dist[i] = make([]float64, len(stops))
+        for j, other := range stops {
+            if math.IsInf(d[idx[other]], 1) {
+                return nil, Path{}, errors.New("Stops are not connected")
+            }
+            dist[i][j] = d[idx[other]]
+        }
+        via[i] = p
+    }
+
+    var tour []int
+    switch strategy {
+    case TourNearestNeighbour:
+        tour = nearestNeighbourTour(dist)
+    case TourChristofides:
+        tour = christofidesTour(dist)
+    default:
+        return nil, Path{}, errors.New("Unknown tour strategy")
+    }
+
+    for improved := improve; improved; {
+        improved = twoOpt(tour, dist) || orOpt(tour, dist)
+    }
+
+    nodes := make([]*Node, len(tour))
+    var path Path
+    path.Path = make([]Edge, 0)
+    for i, stop := range tour {
+        nodes[i] = stops[stop]
+        next := tour[(i+1)%len(tour)]
+        if next == stop {
+            continue
+        }
+        for _, arc := range walkTo(via[stop], idx[stops[next]]) {
+            e := arc.edge
+            path.Path = append(path.Path, Edge{
+                ParentNode: e.ParentNode,
+                ChildNode:  e.ChildNode,
+                Distance:   e.Distance,
+                Properties: e.Properties,
+            })
+            path.Weight += e.Distance
+        }
+    }
+
+    return nodes, path, nil
+}
+
+func nearestNeighbourTour(dist [][]float64) []int {
+
+    visited := make([]bool, len(dist))
+    tour := []int{0}
+    visited[0] = true
+
+    for len(tour) < len(dist) {
+        u := tour[len(tour)-1]
+        best := -1
+        for v := range dist {
+            if !visited[v] && (best < 0 || dist[u][v] < dist[u][best]) {
+                best = v
+            }
+        }
+        visited[best] = true
+        tour = append(tour, best)
+    }
+
+    return tour
+}
+
+func christofidesTour(dist [][]float64) []int {
+
+    n := len(dist)
+
+    // minimum spanning tree by Prim
+    ends := make([][2]int, 0, n)
+    inTree := make([]bool, n)
+    cost := make([]float64, n)
+    from := make([]int, n)
+    for i := range cost {
+        cost[i] = math.Inf(1)
+    }
+    cost[0] = 0
+
+    for range dist {
+        u := -1
+        for v := range dist {
+            if !inTree[v] && (u < 0 || cost[v] < cost[u]) {
+                u = v
+            }
+        }
+        inTree[u] = true
+        if u != 0 {
+            ends = append(ends, [2]int{from[u], u})
+        }
+        for v := range dist {
+            if !inTree[v] && dist[u][v] < cost[v] {
+                cost[v] = dist[u][v]
+                from[v] = u
+            }
+        }
+    }
+
+    // pair up the odd degree stops
+    degree := make([]int, n)
+    for _, e := range ends {
+        degree[e[0]]++
+        degree[e[1]]++
+    }
+    odd := make([]int, 0)
+    for v, d := range degree {
+        if d%2 != 0 {
+            odd = append(odd, v)
+        }
+    }
+    sub := make([][]float64, len(odd))
+    for i, u := range odd {
+        sub[i] = make([]float64, len(odd))
+        for j, v := range odd {
+            sub[i][j] = dist[u][v]
+        }
+    }
+
+    var pairs [][2]int
+    if len(odd) <= maxPostmanOddNodes {
+        pairs, _ = minPairing(sub)
+    } else {
+        pairs = greedyPairing(sub)
+    }
+    for _, p := range pairs {
+        ends = append(ends, [2]int{odd[p[0]], odd[p[1]]})
+    }
+
+    adj := make([][]int, n)
+    for k, e := range ends {
+        adj[e[0]] = append(adj[e[0]], k)
+        adj[e[1]] = append(adj[e[1]], k)
+    }
+
+    // shortcut the Eulerian circuit past stops already visited
+    circuit, _ := hierholzer(adj, ends, 0)
+    visited := make([]bool, n)
+    tour := make([]int, 0, n)
+    for _, v := range circuit {
+        if !visited[v] {
+            visited[v] = true
+            tour = append(tour, v)
+        }
+    }
+
+    return tour
+}
+
+// greedyPairing pairs points by repeatedly taking the closest two that
+// are still unpaired.
+func greedyPairing(dist [][]float64) [][2]int {
+
+    all := make([][2]int, 0)
+    for i := range dist {
+        for j := i + 1; j < len(dist); j++ {
+            all = append(all, [2]int{i, j})
+        }
+    }
+    sort.SliceStable(all, func(a, b int) bool {
+        return dist[all[a][0]][all[a][1]] < dist[all[b][0]][all[b][1]]
+    })
+
+    paired := make([]bool, len(dist))
+    out := make([][2]int, 0, len(dist)/2)
+    for _, p := range all {
+        if !paired[p[0]] && !paired[p[1]] {
+            paired[p[0]] = true
+            paired[p[1]] = true
+            out = append(out, p)
+        }
+    }
+
+    return out
+}
+
+// twoOpt reverses the first section of the tour found that makes it
+// shorter, and reports whether it did.
+func twoOpt(tour []int, dist [][]float64) bool {
+
+    n := len(tour)
+    for i := 0; i < n-2; i++ {
+        for j := i + 2; j < n; j++ {
+            if i == 0 && j == n-1 {
+                continue
+            }
+            a, b := tour[i], tour[i+1]
+            c, d := tour[j], tour[(j+1)%n]
+            if dist[a][c]+dist[b][d] < dist[a][b]+dist[c][d]-tourEpsilon {
+                for l, r := i+1, j; l < r; l, r = l+1, r-1 {
+                    tour[l], tour[r] = tour[r], tour[l]
+                }
+                return true
+            }
+        }
+    }
+
+    return false
+}
+
+// orOpt moves the first section of one to three stops found that makes
+// the tour shorter elsewhere in the tour, possibly reversed, and reports
+// whether it did. The first stop is never moved.
+func orOpt(tour []int, dist [][]float64) bool {
+
+    n := len(tour)
+    for size := 1; size <= 3; size++ {
+        for i := 1; i+size <= n; i++ {
+            first, last := tour[i], tour[i+size-1]
+            prev, next := tour[i-1], tour[(i+size)%n]
+            saved := dist[prev][first] + dist[last][next] - dist[prev][next]
+
+            // the tour without the section, then try every gap
+            rest := make([]int, 0, n-size)
+            rest = append(rest, tour[:i]...)
+            rest = append(rest, tour[i+size:]...)
+
+            for j := range rest {
+                a, b := rest[j], rest[(j+1)%len(rest)]
+                if j == i-1 {
+                    continue
+                }
+                forward := dist[a][first] + dist[last][b] - dist[a][b]
+                backward := dist[a][last] + dist[first][b] - dist[a][b]
+                if forward >= saved-tourEpsilon && backward >= saved-tourEpsilon {
+                    continue
+                }
+
+                section := make([]int, size)
+                copy(section, tour[i:i+size])
+                if backward < forward {
+                    for l, r := 0, size-1; l < r; l, r = l+1, r-1 {
+                        section[l], section[r] = section[r], section[l]
+                    }
+                }
+
+                moved := make([]int, 0, n)
+                moved = append(moved, rest[:j+1]...)
+                moved = append(moved, section...)
+                moved = append(moved, rest[j+1:]...)
+                copy(tour, moved)
+                return true
+            }
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,124 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "math"
+    "strconv"
+    "testing"
+)
+
+// pointGraph links every pair of points by their distance in the plane.
+func pointGraph(points [][2]float64) *Graph {
+
+    g := NewGraph("points")
+    for i := range points {
+        g.AddNode(strconv.Itoa(i), strconv.Itoa(i))
+    }
+    for i := range points {
+        for j := i + 1; j < len(points); j++ {
+            e := NewEdge()
+            e.AddProperty("id", strconv.Itoa(i)+"-"+strconv.Itoa(j))
+            e.SetDistance(math.Hypot(points[i][0]-points[j][0], points[i][1]-points[j][1]))
+            e.Link(g.nodes[i], g.nodes[j])
+        }
+    }
+
+    return g
+}
+
+// shortestTour tries every order of the points after the first.
+func shortestTour(points [][2]float64) float64 {
+
+    order := make([]int, len(points))
+    for i := range order {
+        order[i] = i
+    }
+    best := math.Inf(1)
+    var permute func(k int)
+    permute = func(k int) {
+        if k == len(order) {
+            length := 0.0
+            for i, p := range order {
+                q := order[(i+1)%len(order)]
+                length += math.Hypot(points[p][0]-points[q][0], points[p][1]-points[q][1])
+            }
+            best = math.Min(best, length)
+            return
+        }
+        for i := k; i < len(order); i++ {
+            order[k], order[i] = order[i], order[k]
+            permute(k + 1)
+            order[k], order[i] = order[i], order[k]
+        }
+    }
+    permute(1)
+
+    return best
+}
+
+func TestTour(t *testing.T) {
+
+    square := pointGraph([][2]float64{{0, 0}, {1, 1}, {1, 0}, {0, 1}})
+    for _, strategy := range []TourStrategy{TourNearestNeighbour, TourChristofides} {
+        stops, path, err := square.Tour(square.nodes, strategy, false)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(stops) != 4 || stops[0] != square.nodes[0] || math.Abs(path.Weight-4) > 1e-9 {
+            t.Errorf("strategy %d: square tour of %d stops at %v, want 4 at 4", strategy, len(stops), path.Weight)
+        }
+    }
+
+    points := [][2]float64{{0, 0}, {4, 0}, {1, 0.2}, {3, 3}, {2, 0}, {0, 3}, {3, 1}, {1, 2}}
+    best := shortestTour(points)
+    g := pointGraph(points)
+    // the nearest neighbour tour misses the best one, 2-opt finds it
+    if _, path, _ := g.Tour(g.nodes, TourNearestNeighbour, false); path.Weight < best+1e-6 {
+        t.Errorf("nearest neighbour tour at %v is already the best", path.Weight)
+    }
+    for _, test := range []struct {
+        strategy  TourStrategy
+        improve   bool
+        bound     float64
+    }{
+        {TourNearestNeighbour, true, 1},
+        {TourChristofides, true, 1},
+        {TourChristofides, false, 1.5},
+    } {
+        stops, path, err := g.Tour(g.nodes, test.strategy, test.improve)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(stops) != len(points) || path.Weight < best-1e-9 || path.Weight > test.bound*best+1e-9 {
+            t.Errorf("strategy %d improved %v: tour of %d stops at %v, want at most %v times %v",
+                test.strategy, test.improve, len(stops), path.Weight, test.bound, best)
+        }
+    }
+}
+
+func TestTourPaths(t *testing.T) {
+
+    // a and c are only linked through b
+    g := edgeGraph(t, "a b 1", "b c 2", "x y 1")
+    a, _ := g.GetNodeById("a")
+    c, _ := g.GetNodeById("c")
+    stops, path, err := g.Tour([]*Node{a, c}, TourChristofides, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if nodeNames(stops) != "a c" || len(path.Path) != 4 || path.Weight != 6 {
+        t.Errorf("tour %s walks %d edges at %v, want a c walking 4 at 6", nodeNames(stops), len(path.Path), path.Weight)
+    }
+
+    x, _ := g.GetNodeById("x")
+    if _, _, err := g.Tour([]*Node{a, x}, TourNearestNeighbour, false); err == nil {
+        t.Errorf("a tour between unconnected stops did not fail")
+    }
+    if _, _, err := g.Tour([]*Node{a, a}, TourNearestNeighbour, false); err == nil {
+        t.Errorf("a tour with a repeated stop did not fail")
+    }
+}
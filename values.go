@@ -0,0 +1,500 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "math"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// ValueKind is the type of a property Value.
+type ValueKind int
+
+const (
+    StringKind ValueKind = iota
+    IntKind
+    FloatKind
+    BoolKind
+    TimeKind
+    BytesKind
+    ListKind
+)
+
+var kindNames = []string{"string", "int", "float", "bool", "time", "bytes", "list"}
+
+// String returns the name of the kind, such as "int".
+func (k ValueKind) String() string {
+    if k < 0 || int(k) >= len(kindNames) {
+        return "unknown"
+    }
+    return kindNames[k]
+}
+
+// Value is a typed property value. Every value has a text form, which is
+// what typed setters store in Properties so that AddProperty,
+// GetProperty and the file formats keep working with strings:
+//
+//  int     decimal
+//  float   shortest decimal or exponent form, "NaN", "+Inf" or "-Inf"
+//  bool    "true" or "false"
+//  time    RFC 3339 with nanoseconds
+//  bytes   standard base64
+//  list    JSON array of the items, with times, bytes and infinite or
+//          NaN floats as strings
+type Value struct {
+    kind   ValueKind
+    str    string
+    num    int64
+    real   float64
+    flag   bool
+    when   time.Time
+    raw    []byte
+    list   []Value
+}
+
+// StringValue returns a string value.
+func StringValue(s string) Value { return Value{kind: StringKind, str: s} }
+
+// IntValue returns an integer value.
+func IntValue(i int64) Value { return Value{kind: IntKind, num: i} }
+
+// FloatValue returns a floating point value.
+func FloatValue(f float64) Value { return Value{kind: FloatKind, real: f} }
+
+// BoolValue returns a boolean value.
+func BoolValue(b bool) Value { return Value{kind: BoolKind, flag: b} }
+
+// TimeValue returns a time value.
+func TimeValue(t time.Time) Value { return Value{kind: TimeKind, when: t} }
+
+// BytesValue returns a value holding a copy of b.
+func BytesValue(b []byte) Value {
+    return Value{kind: BytesKind, raw: append([]byte{}, b...)}
+}
+
+// ListValue returns a list of values.
+func ListValue(items ...Value) Value {
+    return Value{kind: ListKind, list: append([]Value{}, items...)}
+}
+
+// Kind returns the type of the value.
+func (v Value) Kind() ValueKind {
+    return v.kind
+}
+
+// Int returns the value if it is an integer.
+func (v Value) Int() (int64, bool) {
+    return v.num, v.kind == IntKind
+}
+
+// Float returns the value if it is a floating point number.
+func (v Value) Float() (float64, bool) {
+    return v.real, v.kind == FloatKind
+}
+
+// Bool returns the value if it is a boolean.
+func (v Value) Bool() (bool, bool) {
+    return v.flag, v.kind == BoolKind
+}
+
+// Time returns the value if it is a time.
+func (v Value) Time() (time.Time, bool) {
+    return v.when, v.kind == TimeKind
+}
+
+// Bytes returns a copy of the value if it is a byte slice.
+func (v Value) Bytes() ([]byte, bool) {
+    if v.kind != BytesKind {
+        return nil, false
+    }
+    return append([]byte{}, v.raw...), true
+}
+
+// List returns the items if the value is a list.
+func (v Value) List() ([]Value, bool) {
+    if v.kind != ListKind {
+        return nil, false
+    }
+    return append([]Value{}, v.list...), true
+}
+
+// String returns the text form of the value.
+func (v Value) String() string {
+
+    switch v.kind {
+    case IntKind:
+        return strconv.FormatInt(v.num, 10)
+    case FloatKind:
+        return strconv.FormatFloat(v.real, 'g', -1, 64)
+    case BoolKind:
+        return strconv.FormatBool(v.flag)
+    case TimeKind:
+        return v.when.Format(time.RFC3339Nano)
+    case BytesKind:
+        return base64.StdEncoding.EncodeToString(v.raw)
+    case ListKind:
+        data, _ := json.Marshal(v.jsonValue())
+        return string(data)
+    }
+    return v.str
+}
+
+// jsonValue returns the value as encoding/json represents it.
+func (v Value) jsonValue() interface{} {
+
+    switch v.kind {
+    case IntKind:
+        return v.num
+    case FloatKind:
+        if math.IsNaN(v.real) || math.IsInf(v.real, 0) {
+            return v.String()
+        }
+        return v.real
+    case BoolKind:
+        return v.flag
+    case ListKind:
+        items := make([]interface{}, len(v.list))
+        for i, item := range v.list {
+            items[i] = item.jsonValue()
+        }
+        return items
+    }
+    return v.String()
+}
+
+// fromJSON converts a decoded JSON value, reading integral numbers as
+// integers and other numbers as floats.
+func fromJSON(x interface{}) (Value, error) {
+
+    switch t := x.(type) {
+    case string:
+        return StringValue(t), nil
+    case bool:
+        return BoolValue(t), nil
+    case json.Number:
+        if i, err := t.Int64(); err == nil {
+            return IntValue(i), nil
+        }
+        f, err := t.Float64()
+        return FloatValue(f), err
+    case []interface{}:
+        items := make([]Value, len(t))
+        for i, item := range t {
+            v, err := fromJSON(item)
+            if err != nil {
+                return Value{}, err
+            }
+            items[i] = v
+        }
+        return ListValue(items...), nil
+    }
+    return Value{}, errors.New("Unsupported list item")
+}
+
+// ParseValue reads the text form of a value of the given kind. List
+// items read back as strings, integers, floats, booleans or lists.
+func ParseValue(kind ValueKind, text string) (Value, error) {
+
+    switch kind {
+    case StringKind:
+        return StringValue(text), nil
+    case IntKind:
+        i, err := strconv.ParseInt(text, 10, 64)
+        return IntValue(i), err
+    case FloatKind:
+        f, err := strconv.ParseFloat(text, 64)
+        return FloatValue(f), err
+    case BoolKind:
+        b, err := strconv.ParseBool(text)
+        return BoolValue(b), err
+    case TimeKind:
+        t, err := time.Parse(time.RFC3339Nano, text)
+        return TimeValue(t), err
+    case BytesKind:
+        b, err := base64.StdEncoding.DecodeString(text)
+        return Value{kind: BytesKind, raw: b}, err
+    case ListKind:
+        dec := json.NewDecoder(bytes.NewReader([]byte(text)))
+        dec.UseNumber()
+        var items []interface{}
+        if err := dec.Decode(&items); err != nil {
+            return Value{}, err
+        }
+        if items == nil {
+            return Value{}, errors.New("List value is null")
+        }
+        return fromJSON(items)
+    }
+    return Value{}, errors.New("Unknown value kind")
+}
+
+// typedValue keeps a value set by a typed setter together with the text
+// it stored, so it is only used while the property still holds that
+// text.
+type typedValue struct {
+    value  Value
+    text   string
+}
+
+// setValue stores v in props as text and remembers it in typed.
+func setValue(lock *sync.RWMutex, props map[string]string, typed *map[string]typedValue, key string, v Value) {
+
+    text := v.String()
+
+    lock.Lock()
+    defer lock.Unlock()
+
+    if *typed == nil {
+        *typed = make(map[string]typedValue)
+    }
+    props[key] = text
+    (*typed)[key] = typedValue{value: v, text: text}
+}
+
+// getValue returns the property as a value of the given kind, parsing
+// its text unless a typed setter stored it and it has not changed since.
+func getValue(lock *sync.RWMutex, props map[string]string, typed *map[string]typedValue, key string, kind ValueKind) (Value, bool) {
+
+    lock.RLock()
+    text, ok := props[key]
+    t, cached := (*typed)[key]
+    lock.RUnlock()
+
+    if !ok {
+        return Value{}, false
+    }
+    if cached && t.text == text && t.value.kind == kind {
+        return t.value, true
+    }
+
+    v, err := ParseValue(kind, text)
+    return v, err == nil
+}
+
+// SetValue sets a property to a typed value, storing its text form. It
+// fails if the graph schema does not allow the value.
+func (n *Node) SetValue(key string, v Value) error {
+
+    if n == nil {
+        return errors.New("Node is nil")
+    }
+
+    if err := n.checkProperty(key, v.String(), true); err != nil {
+        return err
+    }
+    setValue(&n.lock, n.Properties, &n.values, key, v)
+    return nil
+}
+
+// GetValue returns a property as the typed value last set for it, or as
+// a string value if it was set as a string.
+func (n *Node) GetValue(key string) (Value, bool) {
+
+    if n == nil {
+        return Value{}, false
+    }
+
+    n.lock.RLock()
+    text, ok := n.Properties[key]
+    t, cached := n.values[key]
+    n.lock.RUnlock()
+
+    if cached && t.text == text {
+        return t.value, true
+    }
+    return StringValue(text), ok
+}
+
+// SetInt sets a property to an integer.
+func (n *Node) SetInt(key string, i int64) error { return n.SetValue(key, IntValue(i)) }
+
+// SetFloat sets a property to a floating point number.
+func (n *Node) SetFloat(key string, f float64) error { return n.SetValue(key, FloatValue(f)) }
+
+// SetBool sets a property to a boolean.
+func (n *Node) SetBool(key string, b bool) error { return n.SetValue(key, BoolValue(b)) }
+
+// SetTime sets a property to a time.
+func (n *Node) SetTime(key string, t time.Time) error { return n.SetValue(key, TimeValue(t)) }
+
+// SetBytes sets a property to a byte slice.
+func (n *Node) SetBytes(key string, b []byte) error { return n.SetValue(key, BytesValue(b)) }
+
+// SetList sets a property to a list of values.
+func (n *Node) SetList(key string, items ...Value) error { return n.SetValue(key, ListValue(items...)) }
+
+// GetInt returns a property as an integer.
+func (n *Node) GetInt(key string) (int64, bool) {
+    if n == nil {
+        return 0, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, IntKind)
+    return v.num, ok
+}
+
+// GetFloat returns a property as a floating point number.
+func (n *Node) GetFloat(key string) (float64, bool) {
+    if n == nil {
+        return 0, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, FloatKind)
+    return v.real, ok
+}
+
+// GetBool returns a property as a boolean.
+func (n *Node) GetBool(key string) (bool, bool) {
+    if n == nil {
+        return false, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, BoolKind)
+    return v.flag, ok
+}
+
+// GetTime returns a property as a time.
+func (n *Node) GetTime(key string) (time.Time, bool) {
+    if n == nil {
+        return time.Time{}, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, TimeKind)
+    return v.when, ok
+}
+
+// GetBytes returns a property as a byte slice.
+func (n *Node) GetBytes(key string) ([]byte, bool) {
+    if n == nil {
+        return nil, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, BytesKind)
+    if !ok {
+        return nil, false
+    }
+    return v.Bytes()
+}
+
+// GetList returns a property as a list of values.
+func (n *Node) GetList(key string) ([]Value, bool) {
+    if n == nil {
+        return nil, false
+    }
+    v, ok := getValue(&n.lock, n.Properties, &n.values, key, ListKind)
+    if !ok {
+        return nil, false
+    }
+    return v.List()
+}
+
+// SetValue sets a property to a typed value, storing its text form.
+func (e *Edge) SetValue(key string, v Value) {
+
+    if e == nil {
+        return
+    }
+
+    setValue(&e.lock, e.Properties, &e.values, key, v)
+}
+
+// GetValue returns a property as the typed value last set for it, or as
+// a string value if it was set as a string.
+func (e *Edge) GetValue(key string) (Value, bool) {
+
+    if e == nil {
+        return Value{}, false
+    }
+
+    e.lock.RLock()
+    text, ok := e.Properties[key]
+    t, cached := e.values[key]
+    e.lock.RUnlock()
+
+    if cached && t.text == text {
+        return t.value, true
+    }
+    return StringValue(text), ok
+}
+
+// SetInt sets a property to an integer.
+func (e *Edge) SetInt(key string, i int64) { e.SetValue(key, IntValue(i)) }
+
+// SetFloat sets a property to a floating point number.
+func (e *Edge) SetFloat(key string, f float64) { e.SetValue(key, FloatValue(f)) }
+
+// SetBool sets a property to a boolean.
+func (e *Edge) SetBool(key string, b bool) { e.SetValue(key, BoolValue(b)) }
+
+// SetTime sets a property to a time.
+func (e *Edge) SetTime(key string, t time.Time) { e.SetValue(key, TimeValue(t)) }
+
+// SetBytes sets a property to a byte slice.
+func (e *Edge) SetBytes(key string, b []byte) { e.SetValue(key, BytesValue(b)) }
+
+// SetList sets a property to a list of values.
+func (e *Edge) SetList(key string, items ...Value) { e.SetValue(key, ListValue(items...)) }
+
+// GetInt returns a property as an integer.
+func (e *Edge) GetInt(key string) (int64, bool) {
+    if e == nil {
+        return 0, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, IntKind)
+    return v.num, ok
+}
+
+// GetFloat returns a property as a floating point number.
+func (e *Edge) GetFloat(key string) (float64, bool) {
+    if e == nil {
+        return 0, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, FloatKind)
+    return v.real, ok
+}
+
+// GetBool returns a property as a boolean.
+func (e *Edge) GetBool(key string) (bool, bool) {
+    if e == nil {
+        return false, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, BoolKind)
+    return v.flag, ok
+}
+
+// GetTime returns a property as a time.
+func (e *Edge) GetTime(key string) (time.Time, bool) {
+    if e == nil {
+        return time.Time{}, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, TimeKind)
+    return v.when, ok
+}
+
+// GetBytes returns a property as a byte slice.
+func (e *Edge) GetBytes(key string) ([]byte, bool) {
+    if e == nil {
+        return nil, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, BytesKind)
+    if !ok {
+        return nil, false
+    }
+    return v.Bytes()
+}
+
+// GetList returns a property as a list of values.
+func (e *Edge) GetList(key string) ([]Value, bool) {
+    if e == nil {
+        return nil, false
+    }
+    v, ok := getValue(&e.lock, e.Properties, &e.values, key, ListKind)
+    if !ok {
+        return nil, false
+    }
+    return v.List()
+}
@@ -0,0 +1,140 @@
+// Graph Library
+// Copyright (C) 2015 by Todd Moses (todd@toddmoses.com)
+// Licensed under:
+// The GNU Lesser General Public License, version 3.0 (LGPL-3.0)
+
+package graph
+
+import (
+    "math"
+    "testing"
+    "time"
+)
+
+func TestValueText(t *testing.T) {
+
+    when := time.Date(2015, 1, 2, 3, 4, 5, 6, time.UTC)
+    tests := []struct {
+        value  Value
+        text   string
+    }{
+        {StringValue("x y"), "x y"},
+        {IntValue(-42), "-42"},
+        {FloatValue(2.5), "2.5"},
+        {BoolValue(true), "true"},
+        {TimeValue(when), "2015-01-02T03:04:05.000000006Z"},
+        {BytesValue([]byte("hi")), "aGk="},
+        {ListValue(IntValue(1), FloatValue(1.5), StringValue("a"), BoolValue(false), ListValue()), `[1,1.5,"a",false,[]]`},
+        {ListValue(FloatValue(math.Inf(1)), TimeValue(when)), `["+Inf","2015-01-02T03:04:05.000000006Z"]`},
+    }
+
+    for _, test := range tests {
+        if got := test.value.String(); got != test.text {
+            t.Errorf("%v value is %q, want %q", test.value.Kind(), got, test.text)
+            continue
+        }
+        v, err := ParseValue(test.value.Kind(), test.text)
+        if err != nil || v.Kind() != test.value.Kind() || v.String() != test.text {
+            t.Errorf("parsing %q as %v gives %v %q, %v", test.text, test.value.Kind(), v.Kind(), v.String(), err)
+        }
+    }
+
+    // list items keep the kinds JSON has
+    v, _ := ParseValue(ListKind, `[7, 7.5, "s", true, [1]]`)
+    items, ok := v.List()
+    kinds := []ValueKind{IntKind, FloatKind, StringKind, BoolKind, ListKind}
+    if !ok || len(items) != len(kinds) {
+        t.Fatalf("list items %v", items)
+    }
+    for i, item := range items {
+        if item.Kind() != kinds[i] {
+            t.Errorf("list item %d is %v, want %v", i, item.Kind(), kinds[i])
+        }
+    }
+    if i, ok := items[0].Int(); !ok || i != 7 {
+        t.Errorf("first item %v %v, want 7", i, ok)
+    }
+    if _, ok := items[0].Float(); ok {
+        t.Errorf("an integer item reads as a float")
+    }
+
+    bad := []struct {
+        kind  ValueKind
+        text  string
+    }{
+        {IntKind, "1.5"}, {FloatKind, "much"}, {BoolKind, "maybe"}, {TimeKind, "yesterday"},
+        {BytesKind, "!"}, {ListKind, "null"}, {ListKind, `{"a": 1}`}, {ListKind, "[1"}, {ValueKind(99), "1"},
+    }
+    for _, test := range bad {
+        if _, err := ParseValue(test.kind, test.text); err == nil {
+            t.Errorf("parsing %q as %v did not fail", test.text, test.kind)
+        }
+    }
+}
+
+func TestTypedProperties(t *testing.T) {
+
+    g := NewGraph("")
+    n, _ := g.AddNode("n", "N")
+    if err := n.SetInt("age", 42); err != nil {
+        t.Fatal(err)
+    }
+    if n.GetProperty("age") != "42" {
+        t.Errorf("text form %q, want 42", n.GetProperty("age"))
+    }
+    if v, ok := n.GetValue("age"); !ok || v.Kind() != IntKind {
+        t.Errorf("value %v of kind %v, want an integer", v, v.Kind())
+    }
+    if f, ok := n.GetFloat("age"); !ok || f != 42 {
+        t.Errorf("integer read as float gives %v %v, want 42", f, ok)
+    }
+    if _, ok := n.GetBool("age"); ok {
+        t.Errorf("integer read as a boolean")
+    }
+
+    // setting the text drops the typed value
+    n.AddProperty("age", "43")
+    if v, _ := n.GetValue("age"); v.Kind() != StringKind {
+        t.Errorf("value after AddProperty is %v, want a string", v.Kind())
+    }
+    if i, ok := n.GetInt("age"); !ok || i != 43 {
+        t.Errorf("parsed %v %v, want 43", i, ok)
+    }
+    if _, ok := n.GetInt("missing"); ok {
+        t.Errorf("missing property read as an integer")
+    }
+
+    raw := []byte("abc")
+    n.SetBytes("raw", raw)
+    raw[0] = 'x'
+    got, _ := n.GetBytes("raw")
+    got[1] = 'x'
+    if again, _ := n.GetBytes("raw"); string(again) != "abc" {
+        t.Errorf("bytes %q, want abc unchanged by callers", again)
+    }
+
+    when := time.Date(2015, 6, 1, 12, 0, 0, 0, time.FixedZone("", 3600))
+    n.SetTime("joined", when)
+    if got, ok := n.GetTime("joined"); !ok || !got.Equal(when) {
+        t.Errorf("time %v %v, want %v", got, ok, when)
+    }
+
+    e := NewEdge()
+    e.SetList("tags", StringValue("a"), IntValue(2))
+    e.SetBool("seen", true)
+    if e.GetProperty("tags") != `["a",2]` || e.GetProperty("seen") != "true" {
+        t.Errorf("edge text forms %q and %q", e.GetProperty("tags"), e.GetProperty("seen"))
+    }
+    if tags, ok := e.GetList("tags"); !ok || len(tags) != 2 || tags[1].Kind() != IntKind {
+        t.Errorf("edge list %v %v", tags, ok)
+    }
+    e.AddProperty("count", "9")
+    if i, ok := e.GetInt("count"); !ok || i != 9 {
+        t.Errorf("edge integer %v %v, want 9", i, ok)
+    }
+
+    var none *Node
+    if err := none.SetInt("age", 1); err == nil {
+        t.Errorf("setting a value on a nil node did not fail")
+    }
+}